@@ -0,0 +1,199 @@
+package xk6_vechain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// parseABI parses an ABI given either as a JSON string or as the JS array itself.
+func parseABI(raw json.RawMessage) (*abi.ABI, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("abi is required")
+	}
+
+	definition := string(raw)
+	if strings.HasPrefix(definition, `"`) {
+		if err := json.Unmarshal(raw, &definition); err != nil {
+			return nil, err
+		}
+	}
+
+	contractABI, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		return nil, fmt.Errorf("invalid abi: %w", err)
+	}
+	return &contractABI, nil
+}
+
+// abiArgs converts the JS values of the arguments to the Go types the ABI packer expects.
+func abiArgs(inputs abi.Arguments, values []interface{}) ([]interface{}, error) {
+	if len(values) != len(inputs) {
+		return nil, fmt.Errorf("expected %d arguments, got %d", len(inputs), len(values))
+	}
+
+	args := make([]interface{}, len(values))
+	for i, input := range inputs {
+		arg, err := abiValue(input.Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d (%s %s): %w", i, input.Type, input.Name, err)
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+// abiValue converts a JS value to the Go type of the ABI type. Integers may be given as numbers,
+// bigints, or decimal or 0x-prefixed hex strings, and must fit the type, and bytes as hex strings.
+func abiValue(t abi.Type, value interface{}) (interface{}, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := bigValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIntRange(t, n); err != nil {
+			return nil, err
+		}
+		goType := t.GetType()
+		if goType.Kind() == reflect.Ptr {
+			return n, nil
+		}
+		if t.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(goType).Interface(), nil
+		}
+		return reflect.ValueOf(n.Int64()).Convert(goType).Interface(), nil
+
+	case abi.BoolTy:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean, got %T", value)
+		}
+		return b, nil
+
+	case abi.StringTy:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", value)
+		}
+		return s, nil
+
+	case abi.AddressTy:
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("expected an address, got %v", value)
+		}
+		return common.HexToAddress(s), nil
+
+	case abi.BytesTy:
+		return bytesValue(value)
+
+	case abi.FixedBytesTy:
+		b, err := bytesValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != t.Size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", t.Size, len(b))
+		}
+		array := reflect.New(t.GetType()).Elem()
+		reflect.Copy(array, reflect.ValueOf(b))
+		return array.Interface(), nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		elements, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %T", value)
+		}
+		if t.T == abi.ArrayTy && len(elements) != t.Size {
+			return nil, fmt.Errorf("expected %d elements, got %d", t.Size, len(elements))
+		}
+
+		var result reflect.Value
+		if t.T == abi.SliceTy {
+			result = reflect.MakeSlice(t.GetType(), len(elements), len(elements))
+		} else {
+			result = reflect.New(t.GetType()).Elem()
+		}
+		for i, element := range elements {
+			v, err := abiValue(*t.Elem, element)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			result.Index(i).Set(reflect.ValueOf(v))
+		}
+		return result.Interface(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// bigValue converts a JS number, bigint, or decimal or hex string to a big integer.
+func bigValue(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int64:
+		return big.NewInt(v), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case float64:
+		if math.IsInf(v, 0) || v != math.Trunc(v) {
+			return nil, fmt.Errorf("expected an integer, got %v", v)
+		}
+		n, _ := big.NewFloat(v).Int(nil)
+		return n, nil
+	case string:
+		base, digits := 10, v
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "-0x") {
+			base, digits = 16, strings.Replace(v, "0x", "", 1)
+		}
+		n, ok := new(big.Int).SetString(digits, base)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("expected an integer, got %T", value)
+	}
+}
+
+// checkIntRange checks that the integer fits the int or uint ABI type, so that it is not truncated
+// when packed.
+func checkIntRange(t abi.Type, n *big.Int) error {
+	if t.T == abi.UintTy {
+		if n.Sign() < 0 {
+			return fmt.Errorf("%s cannot be negative, got %s", t, n)
+		}
+		if n.BitLen() > t.Size {
+			return fmt.Errorf("%s out of range, got %s", t, n)
+		}
+		return nil
+	}
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+		return fmt.Errorf("%s out of range, got %s", t, n)
+	}
+	return nil
+}
+
+// bytesValue converts a hex string to bytes.
+func bytesValue(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a hex string, got %T", value)
+	}
+	if s == "0x" {
+		return []byte{}, nil
+	}
+	return hexutil.Decode(s)
+}
@@ -0,0 +1,109 @@
+package xk6_vechain
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+func TestAbiValueIntegers(t *testing.T) {
+	bigInt := func(s string) *big.Int {
+		n, _ := new(big.Int).SetString(s, 0)
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		typ      string
+		value    interface{}
+		expected interface{}
+		err      string
+	}{
+		{name: "uint8 max", typ: "uint8", value: float64(255), expected: uint8(255)},
+		{name: "uint8 overflow", typ: "uint8", value: float64(256), err: "uint8 out of range, got 256"},
+		{name: "uint8 negative", typ: "uint8", value: float64(-1), err: "uint8 cannot be negative, got -1"},
+		{name: "int8 min", typ: "int8", value: float64(-128), expected: int8(-128)},
+		{name: "int8 max", typ: "int8", value: float64(127), expected: int8(127)},
+		{name: "int8 overflow", typ: "int8", value: float64(128), err: "int8 out of range, got 128"},
+		{name: "int8 underflow", typ: "int8", value: float64(-129), err: "int8 out of range, got -129"},
+		{name: "uint64 max", typ: "uint64", value: "0xffffffffffffffff", expected: uint64(1<<64 - 1)},
+		{name: "uint64 overflow", typ: "uint64", value: "0x10000000000000000", err: "uint64 out of range, got 18446744073709551616"},
+		{name: "uint64 number beyond int64", typ: "uint64", value: float64(1 << 63), expected: uint64(1 << 63)},
+		{name: "uint64 large number", typ: "uint64", value: 1e20, err: "uint64 out of range, got 100000000000000000000"},
+		{name: "int64 negative", typ: "int64", value: int64(-5), expected: int64(-5)},
+		{name: "uint256 decimal", typ: "uint256", value: "1000000000000000000000", expected: bigInt("1000000000000000000000")},
+		{name: "uint256 bigint", typ: "uint256", value: big.NewInt(7), expected: big.NewInt(7)},
+		{name: "uint256 negative", typ: "uint256", value: "-1", err: "uint256 cannot be negative, got -1"},
+		{name: "int256 negative hex", typ: "int256", value: "-0x10", expected: big.NewInt(-16)},
+		{name: "int256 min", typ: "int256", value: "-0x8000000000000000000000000000000000000000000000000000000000000000", expected: new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255))},
+		{name: "int256 overflow", typ: "int256", value: "0x8000000000000000000000000000000000000000000000000000000000000000", err: "int256 out of range"},
+		{name: "fraction", typ: "uint32", value: 1.5, err: "expected an integer, got 1.5"},
+		{name: "invalid string", typ: "uint32", value: "ten", err: `invalid integer "ten"`},
+		{name: "boolean", typ: "uint32", value: true, err: "expected an integer, got bool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, err := abi.NewType(tt.typ, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := abiValue(typ, tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected an error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Fatalf("expected %T %v, got %T %v", tt.expected, tt.expected, value, value)
+			}
+		})
+	}
+}
+
+func TestAbiValueOtherTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		value    interface{}
+		expected interface{}
+		err      string
+	}{
+		{name: "fixed bytes", typ: "bytes2", value: "0x0102", expected: [2]byte{1, 2}},
+		{name: "fixed bytes of the wrong size", typ: "bytes2", value: "0x01", err: "expected 2 bytes, got 1"},
+		{name: "empty bytes", typ: "bytes", value: "0x", expected: []byte{}},
+		{name: "address", typ: "address", value: "0x1", err: "expected an address, got 0x1"},
+		{name: "array of the wrong length", typ: "uint8[2]", value: []interface{}{float64(1)}, err: "expected 2 elements, got 1"},
+		{name: "element out of range", typ: "uint8[]", value: []interface{}{float64(1), float64(300)}, err: "element 1: uint8 out of range, got 300"},
+		{name: "slice", typ: "uint16[]", value: []interface{}{float64(1), "0x2"}, expected: []uint16{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, err := abi.NewType(tt.typ, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := abiValue(typ, tt.value)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected the error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Fatalf("expected %T %v, got %T %v", tt.expected, tt.expected, value, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// abiRegistry maps contract addresses to their ABI, so that the logs the extension returns can be decoded.
+type abiRegistry struct {
+	mu   sync.RWMutex
+	abis map[common.Address]*abi.ABI
+}
+
+// abiRegistries holds the abiRegistry of each node URL.
+var abiRegistries sync.Map
+
+// abisFor returns the process-wide ABI registry of the node, which knows the built-in contracts.
+func abisFor(url string) *abiRegistry {
+	if registry, ok := abiRegistries.Load(url); ok {
+		return registry.(*abiRegistry)
+	}
+
+	registry := &abiRegistry{abis: make(map[common.Address]*abi.ABI)}
+	for _, builtin := range []*builtins.Contract{
+		builtins.VTHO,
+		builtins.Authority,
+		builtins.Executor,
+		builtins.Extension,
+		builtins.Prototype,
+		builtins.Params,
+	} {
+		registry.register(builtin.Address, builtin.ABI)
+	}
+
+	actual, _ := abiRegistries.LoadOrStore(url, registry)
+	return actual.(*abiRegistry)
+}
+
+func (r *abiRegistry) register(address common.Address, contractABI *abi.ABI) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.abis[address] = contractABI
+}
+
+// abi returns the ABI registered for the address, or nil.
+func (r *abiRegistry) abi(address common.Address) *abi.ABI {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.abis[address]
+}
+
+// decode returns the name and arguments of the event, or nil when the address has no registered ABI
+// or the ABI does not contain the event.
+func (r *abiRegistry) decode(address common.Address, topics []common.Hash, data string) map[string]interface{} {
+	r.mu.RLock()
+	contractABI, ok := r.abis[address]
+	r.mu.RUnlock()
+	if !ok || len(topics) == 0 {
+		return nil
+	}
+
+	event, err := contractABI.EventByID(topics[0])
+	if err != nil {
+		return nil
+	}
+
+	args := make(map[string]interface{})
+	raw, err := hexutil.Decode(data)
+	if err != nil {
+		return nil
+	}
+	if err := event.Inputs.NonIndexed().UnpackIntoMap(args, raw); err != nil {
+		return nil
+	}
+
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, topics[1:]); err != nil {
+		return nil
+	}
+
+	for name, value := range args {
+		args[name] = jsValue(value)
+	}
+	return map[string]interface{}{
+		"name": event.Name,
+		"args": args,
+	}
+}
+
+// jsValue converts a decoded ABI value to a value that survives the trip to JS without losing precision.
+// Integers of 64 bits and more become decimal strings whatever their value, so that the type returned
+// for an output does not depend on its magnitude, addresses and bytes become hex strings, arrays slices,
+// and tuples objects keyed by the names of their components.
+func jsValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case common.Hash:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			values[i] = jsValue(rv.Index(i).Interface())
+		}
+		return values
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			name := field.Tag.Get("json")
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = jsValue(rv.Field(i).Interface())
+		}
+		return fields
+	default:
+		return value
+	}
+}
+
+// RegisterAbi registers the ABI of the contract at the address, so that the events it emits are decoded
+// in the logs and receipts the client returns. The registration is shared by all VUs, and the built-in
+// contracts and deployed toolchain contracts are registered automatically.
+func (c *Client) RegisterAbi(address string, abiJSON string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("invalid address %q", address)
+	}
+
+	contractABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("invalid ABI: %w", err)
+	}
+
+	abisFor(c.opts.URL).register(common.HexToAddress(address), &contractABI)
+	return nil
+}
+
+// Receipt returns the receipt of the transaction as served by the node, with every event of a
+// registered contract decoded into a decoded field holding its name and arguments, and the outcome
+// of every clause in a clauses field, which names the clause that reverted the transaction.
+func (c *Client) Receipt(id string) (map[string]interface{}, error) {
+	receipt, err := c.thor.Client.TransactionReceipt(common.HexToHash(id))
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeReceipt(receipt)
+}
+
+// decodeReceipt converts the receipt to JSON, decoding the events of the registered ABIs and adding the
+// status of every clause.
+func (c *Client) decodeReceipt(receipt *client.TransactionReceipt) (map[string]interface{}, error) {
+	decoded, err := toJSON([]*client.TransactionReceipt{receipt})
+	if err != nil {
+		return nil, err
+	}
+
+	abis := abisFor(c.opts.URL)
+	outputs, _ := decoded[0]["outputs"].([]interface{})
+	for i, output := range receipt.Outputs {
+		events, _ := outputs[i].(map[string]interface{})["events"].([]interface{})
+		for j, event := range output.Events {
+			if args := abis.decode(event.Address, event.Topics, event.Data); args != nil {
+				events[j].(map[string]interface{})["decoded"] = args
+			}
+		}
+	}
+
+	statuses, _, err := c.clauseStatuses(receipt.Meta.TxID, receipt)
+	if err != nil {
+		return nil, err
+	}
+	clauses := make([]map[string]interface{}, len(statuses))
+	for i, status := range statuses {
+		clauses[i] = map[string]interface{}{
+			"index":   status.Index,
+			"status":  status.Status,
+			"vmError": status.VMError,
+		}
+	}
+	decoded[0]["clauses"] = clauses
+
+	return decoded[0], nil
+}
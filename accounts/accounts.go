@@ -1,6 +1,16 @@
 package accounts
 
-import "github.com/ethereum/go-ethereum/crypto"
+import (
+	"errors"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/hdwallet"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// defaultMnemonicStrength is the entropy, in bits, used when no strength is given (12 words).
+const defaultMnemonicStrength = 128
 
 type Account struct {
 }
@@ -17,3 +27,62 @@ func (a *Account) Generate() map[string]string {
 
 	return account
 }
+
+// GeneratedAccount is a fresh random account, with its private key hex encoded.
+type GeneratedAccount struct {
+	Address    string `js:"address"`
+	PrivateKey string `js:"privateKey"`
+}
+
+// GenerateAccounts returns the amount of fresh random accounts, e.g. never-before-seen users of an
+// onboarding flow. Unlike generate, the private keys are hex encoded, as privateKeys expects them.
+func (a *Account) GenerateAccounts(amount int) ([]GeneratedAccount, error) {
+	return New(amount)
+}
+
+// New returns the amount of fresh random accounts.
+func New(amount int) ([]GeneratedAccount, error) {
+	if amount <= 0 {
+		return nil, errors.New("amount must be greater than 0")
+	}
+
+	generated := make([]GeneratedAccount, amount)
+	for i := range generated {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		generated[i] = GeneratedAccount{
+			Address:    crypto.PubkeyToAddress(key.PublicKey).Hex(),
+			PrivateKey: hexutil.Encode(crypto.FromECDSA(key)),
+		}
+	}
+	return generated, nil
+}
+
+// GenerateMnemonic returns a new BIP-39 mnemonic using strength bits of entropy.
+// The strength must be a multiple of 32 between 128 and 256, and defaults to 128 when 0.
+// The mnemonic is not logged, as it gives access to every derived account. A script that needs to
+// reproduce a run keeps it itself.
+func (a *Account) GenerateMnemonic(strength int) (string, error) {
+	if strength == 0 {
+		strength = defaultMnemonicStrength
+	}
+
+	mnemonic, err := hdwallet.NewMnemonic(strength)
+	if err != nil {
+		return "", err
+	}
+
+	return mnemonic, nil
+}
+
+// IsValidMnemonic reports whether the phrase is a valid BIP-39 mnemonic.
+func (a *Account) IsValidMnemonic(phrase string) bool {
+	return IsValidMnemonic(phrase)
+}
+
+// IsValidMnemonic reports whether the phrase is a valid BIP-39 mnemonic, including its checksum.
+func IsValidMnemonic(phrase string) bool {
+	return bip39.IsMnemonicValid(phrase)
+}
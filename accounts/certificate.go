@@ -0,0 +1,93 @@
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/certificate"
+	"github.com/darrenvechain/thor-go-sdk/crypto/hash"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CertificateRequest is the content of a VIP-192 certificate to sign.
+type CertificateRequest struct {
+	// Purpose is either "identification" or "agreement".
+	Purpose string `js:"purpose"`
+	Payload struct {
+		Type    string `js:"type"`
+		Content string `js:"content"`
+	} `js:"payload"`
+	Domain string `js:"domain"`
+	// Timestamp is in unix seconds, now when not set.
+	Timestamp uint64 `js:"timestamp"`
+}
+
+// SignedCertificate is a signed certificate, as JSON in the format Connex produces, along with the
+// hex encoded signature.
+type SignedCertificate struct {
+	JSON      string `js:"json"`
+	Signature string `js:"signature"`
+}
+
+// SignCertificate creates the VIP-192 certificate of the request, signed by the hex encoded private key,
+// for load testing backends that verify certificates.
+func (a *Account) SignCertificate(privateKey string, request CertificateRequest) (*SignedCertificate, error) {
+	switch request.Purpose {
+	case "identification", "agreement":
+	default:
+		return nil, fmt.Errorf("unknown purpose %q, expected \"identification\" or \"agreement\"", request.Purpose)
+	}
+	if request.Payload.Type == "" {
+		return nil, errors.New("the payload needs a type, e.g. \"text\"")
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+	if err != nil {
+		return nil, errors.New("invalid private key")
+	}
+	if request.Timestamp == 0 {
+		request.Timestamp = uint64(time.Now().Unix())
+	}
+
+	cert := certificate.Certificate{
+		Domain:    request.Domain,
+		Payload:   certificate.Payload{Type: request.Payload.Type, Content: request.Payload.Content},
+		Purpose:   request.Purpose,
+		Signer:    strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex()),
+		Timestamp: request.Timestamp,
+	}
+	encoded, err := encodeCertificate(cert)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(hash.Blake2b(encoded).Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := encodeCertificate(struct {
+		certificate.Certificate
+		Signature string `json:"signature"`
+	}{cert, hexutil.Encode(signature)})
+	if err != nil {
+		return nil, err
+	}
+	return &SignedCertificate{JSON: string(signed), Signature: hexutil.Encode(signature)}, nil
+}
+
+// encodeCertificate encodes the certificate the way Connex does before hashing it: with sorted keys,
+// as the fields are declared, and without escaping HTML characters.
+func encodeCertificate(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode certificate: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,49 @@
+package xk6_vechain
+
+import (
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/js/promises"
+)
+
+// async runs fn off the event loop and returns a promise settled with its result,
+// so that scripts can await slow calls while the VU keeps running other work.
+func (c *Client) async(fn func() (any, error)) *sobek.Promise {
+	promise, resolve, reject := promises.New(c.vu)
+	go func() {
+		v, err := fn()
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(v)
+	}()
+	return promise
+}
+
+// FundAsync is the promise-returning variant of Fund.
+func (c *Client) FundAsync(start interface{}, amount string) *sobek.Promise {
+	return c.async(func() (any, error) {
+		return nil, c.Fund(start, amount)
+	})
+}
+
+// DeployToolchainAsync is the promise-returning variant of DeployToolchain.
+func (c *Client) DeployToolchainAsync(amount int) *sobek.Promise {
+	return c.async(func() (any, error) {
+		return c.DeployToolchain(amount)
+	})
+}
+
+// NewToolchainTransactionAsync is the promise-returning variant of NewToolchainTransaction.
+func (c *Client) NewToolchainTransactionAsync(address string, options map[string]interface{}) *sobek.Promise {
+	return c.async(func() (any, error) {
+		return c.NewToolchainTransaction(address, options)
+	})
+}
+
+// SendToolchainTransactionAsync is the promise-returning variant of SendToolchainTransaction.
+func (c *Client) SendToolchainTransactionAsync(address string, options map[string]interface{}) *sobek.Promise {
+	return c.async(func() (any, error) {
+		return c.SendToolchainTransaction(address, options)
+	})
+}
@@ -0,0 +1,137 @@
+package xk6_vechain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// inspectBatch is the number of clauses simulated per request.
+const inspectBatch = 100
+
+// batchCallArgs is a read call of batchCall, given either as raw data or as a method and its arguments.
+type batchCallArgs struct {
+	To   string `json:"to"`
+	Data string `json:"data,omitempty"`
+	// Method is called with Args, encoded with the ABI, or the ABI registered for the address.
+	Method string          `json:"method,omitempty"`
+	Args   []interface{}   `json:"args,omitempty"`
+	ABI    json.RawMessage `json:"abi,omitempty"`
+}
+
+// BatchCallResult is the outcome of a read call of batchCall. Value is the decoded return value, set
+// for calls given as a method, the same way as contract.call.
+type BatchCallResult struct {
+	Data     string      `js:"data"`
+	Value    interface{} `js:"value"`
+	Reverted bool        `js:"reverted"`
+	VMError  string      `js:"vmError"`
+}
+
+// BatchCall executes many read calls in as few simulations as possible against the best block, and
+// returns their results in order. A reverted call does not fail the batch, it is marked as reverted.
+func (c *Client) BatchCall(calls []map[string]interface{}) ([]BatchCallResult, error) {
+	clauses := make([]*transaction.Clause, len(calls))
+	methods := make([]*abi.Method, len(calls))
+	for i, call := range calls {
+		var args batchCallArgs
+		if err := decodeOptions(call, &args); err != nil {
+			return nil, fmt.Errorf("invalid call at index %d: %w", i, err)
+		}
+		to, err := parseAddress(args.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid call at index %d: %w", i, err)
+		}
+
+		var data []byte
+		switch {
+		case args.Method != "":
+			contractABI, err := c.callABI(args)
+			if err != nil {
+				return nil, fmt.Errorf("invalid call at index %d: %w", i, err)
+			}
+			contract := &Contract{abi: contractABI}
+			if methods[i], data, err = contract.pack(args.Method, args.Args); err != nil {
+				return nil, fmt.Errorf("invalid call at index %d: %w", i, err)
+			}
+		case args.Data != "":
+			if data, err = hexutil.Decode(args.Data); err != nil {
+				return nil, fmt.Errorf("invalid call at index %d: invalid data: %w", i, err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid call at index %d: either data or method is required", i)
+		}
+		clauses[i] = transaction.NewClause(&to).WithData(data).WithValue(big.NewInt(0))
+	}
+
+	inspections, err := c.inspectEach(clauses)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchCallResult, len(inspections))
+	for i, inspection := range inspections {
+		results[i] = BatchCallResult{
+			Data:     inspection.Data,
+			Reverted: inspection.Reverted || inspection.VmError != "",
+			VMError:  inspection.VmError,
+		}
+		if results[i].Reverted || methods[i] == nil {
+			continue
+		}
+
+		data, err := hexutil.Decode(inspection.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid return data of call %d: %w", i, err)
+		}
+		outputs, err := methods[i].Outputs.Unpack(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack call %d: %w", i, err)
+		}
+		switch len(outputs) {
+		case 0:
+		case 1:
+			results[i].Value = jsValue(outputs[0])
+		default:
+			results[i].Value = jsValue(outputs)
+		}
+	}
+	return results, nil
+}
+
+// callABI returns the ABI of the call, or the one registered for its address.
+func (c *Client) callABI(args batchCallArgs) (*abi.ABI, error) {
+	if len(args.ABI) > 0 {
+		return parseABI(args.ABI)
+	}
+	address, _ := parseAddress(args.To)
+	if contractABI := abisFor(c.opts.URL).abi(address); contractABI != nil {
+		return contractABI, nil
+	}
+	return nil, fmt.Errorf("no abi registered for %s, pass the abi", args.To)
+}
+
+// inspectEach simulates the clauses against the best block, inspectBatch at a time, and returns one
+// result per clause. A simulation stops at the first reverted clause, so the clauses after it are
+// simulated again in the next request.
+func (c *Client) inspectEach(clauses []*transaction.Clause) ([]client.InspectResponse, error) {
+	results := make([]client.InspectResponse, 0, len(clauses))
+	for len(results) < len(clauses) {
+		start := len(results)
+		end := min(start+inspectBatch, len(clauses))
+		inspections, err := c.thor.Client.Inspect(client.InspectRequest{Clauses: clauses[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate clauses %d to %d: %w", start, end-1, err)
+		}
+		if len(inspections) == 0 {
+			return nil, fmt.Errorf("simulation of clauses %d to %d returned no result", start, end-1)
+		}
+		results = append(results, inspections...)
+	}
+	return results, nil
+}
@@ -0,0 +1,288 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/xk6-vechain/random"
+	"github.com/darrenvechain/xk6-vechain/toolchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// bridgeViaLogs is an event found by polling the logs API of the observer.
+	bridgeViaLogs = "logs"
+	// bridgeViaSubscription is an event pushed by the event subscription of the observer.
+	bridgeViaSubscription = "subscription"
+	// defaultLogsPollInterval is how often the logs of the observer are polled unless logsPollInterval is set.
+	defaultLogsPollInterval = time.Second
+	// bridgeConfirmTimeout is how long the events still unseen at the end are waited for.
+	bridgeConfirmTimeout = time.Minute
+)
+
+// bridgeOptions configures runBridgedEvents.
+type bridgeOptions struct {
+	// Contract is the toolchain contract emitting the events.
+	Contract string `json:"contract"`
+	// Observer is the URL of the node the events are watched on, as a relayer would.
+	Observer string `json:"observer"`
+	// TPS is the number of events emitted per second.
+	TPS float64 `json:"tps"`
+	// Duration is how long events are emitted, e.g. "5m".
+	Duration string `json:"duration"`
+	// LogsPollInterval is how often the logs API of the observer is polled, e.g. "1s".
+	LogsPollInterval string `json:"logsPollInterval,omitempty"`
+	// MaxInFlight caps the number of emissions in progress at once.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+}
+
+// BridgeResult is the outcome of runBridgedEvents. The unseen counts are the events the observer did not
+// surface through each way by the end.
+type BridgeResult struct {
+	Emitted              uint64 `js:"emitted"`
+	Failed               uint64 `js:"failed"`
+	Skipped              uint64 `js:"skipped"`
+	SeenInLogs           uint64 `js:"seenInLogs"`
+	SeenInSubscription   uint64 `js:"seenInSubscription"`
+	UnseenInLogs         uint64 `js:"unseenInLogs"`
+	UnseenInSubscription uint64 `js:"unseenInSubscription"`
+}
+
+// bridgeWatch tracks the emitted events that were not seen yet through one way.
+type bridgeWatch struct {
+	via     string
+	pending sync.Map // common.Hash -> time.Time of the submission
+	unseen  atomic.Int64
+	seen    atomic.Uint64
+}
+
+// emitted starts waiting for the event.
+func (w *bridgeWatch) emitted(id common.Hash, submitted time.Time) {
+	w.unseen.Add(1)
+	w.pending.Store(id, submitted)
+}
+
+// observe records the confirmation latency of the event, if it was emitted and not seen yet.
+func (w *bridgeWatch) observe(c *Client, id common.Hash) {
+	submitted, ok := w.pending.LoadAndDelete(id)
+	if !ok {
+		return
+	}
+	w.unseen.Add(-1)
+	w.seen.Add(1)
+	c.pushSample(c.metrics.BridgeLatency, metrics.D(time.Since(submitted.(time.Time))), map[string]string{"via": w.via})
+}
+
+// RunBridgedEvents models the requirements of a bridge relayer, blocking until it is over. Toolchain
+// events are emitted at the rate through this client's node, and watched on the observer node both
+// through the logs API and the event subscription. The time from submission until the event is visible
+// at the observer is recorded in vechain_bridge_event_latency, tagged via logs or subscription.
+func (c *Client) RunBridgedEvents(options map[string]interface{}) (*BridgeResult, error) {
+	var opts bridgeOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	contract, err := parseAddress(opts.Contract)
+	if err != nil {
+		return nil, fmt.Errorf("invalid contract: %w", err)
+	}
+	if opts.Observer == "" {
+		return nil, errors.New("observer is required")
+	}
+	if opts.TPS <= 0 {
+		return nil, errors.New("tps must be greater than 0")
+	}
+	duration, err := time.ParseDuration(opts.Duration)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q", opts.Duration)
+	}
+	pollInterval := defaultLogsPollInterval
+	if opts.LogsPollInterval != "" {
+		if pollInterval, err = time.ParseDuration(opts.LogsPollInterval); err != nil || pollInterval <= 0 {
+			return nil, fmt.Errorf("invalid logsPollInterval %q", opts.LogsPollInterval)
+		}
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultInjectorInFlight
+	}
+
+	observer, err := client.New(opts.Observer, c.http)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", opts.Observer, err)
+	}
+	if tag := observer.ChainTag(); tag != c.chainTag {
+		return nil, fmt.Errorf("observer %s is on chain %#x, not %#x", opts.Observer, tag, c.chainTag)
+	}
+
+	var (
+		logs         = &bridgeWatch{via: bridgeViaLogs}
+		subscription = &bridgeWatch{via: bridgeViaSubscription}
+		stop         = make(chan struct{})
+		watchers     sync.WaitGroup
+	)
+
+	url := subscriptionURL(opts.Observer, "/subscriptions/event?addr="+contract.Hex()+"&t0="+toolchain.EventTopic().Hex())
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", url, err)
+	}
+	defer conn.Close()
+	go func() {
+		for {
+			var event client.EventLog
+			if err := conn.ReadJSON(&event); err != nil {
+				return
+			}
+			if len(event.Topics) > 2 {
+				subscription.observe(c, event.Topics[2])
+			}
+		}
+	}()
+
+	best, err := observer.BestBlock()
+	if err != nil {
+		return nil, err
+	}
+	watchers.Add(1)
+	go func() {
+		defer watchers.Done()
+		c.pollBridgedEvents(observer, contract, best.Number, pollInterval, logs, stop)
+	}()
+	defer func() {
+		close(stop)
+		watchers.Wait()
+	}()
+
+	result := &BridgeResult{}
+	var (
+		wg              sync.WaitGroup
+		emitted, failed atomic.Uint64
+		inFlight        = make(chan struct{}, opts.MaxInFlight)
+		ctx             = c.vu.Context()
+		interval        = time.Duration(float64(time.Second) / opts.TPS)
+		started         = time.Now()
+		end             = started.Add(duration)
+	)
+
+	for n := 0; ; n++ {
+		next := started.Add(time.Duration(n) * interval)
+		if !next.Before(end) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case <-time.After(time.Until(next)):
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			result.Skipped++
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			if err := c.emitBridgedEvent(contract, logs, subscription); err != nil {
+				failed.Add(1)
+				return
+			}
+			emitted.Add(1)
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(bridgeConfirmTimeout)
+	for (logs.unseen.Load() > 0 || subscription.unseen.Load() > 0) && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(receiptPollInterval):
+		}
+	}
+
+	result.Emitted = emitted.Load()
+	result.Failed = failed.Load()
+	result.SeenInLogs = logs.seen.Load()
+	result.SeenInSubscription = subscription.seen.Load()
+	result.UnseenInLogs = uint64(logs.unseen.Load())
+	result.UnseenInSubscription = uint64(subscription.unseen.Load())
+	return result, nil
+}
+
+// emitBridgedEvent sends a transaction emitting an event with a unique id, and waits for it at the observer.
+func (c *Client) emitBridgedEvent(contract common.Address, watches ...*bridgeWatch) error {
+	id := common.BytesToHash(random.Bytes(32))
+	clause, err := toolchain.EventClause(c.thor, contract, id)
+	if err != nil {
+		return err
+	}
+
+	signer, err := c.randomSigner()
+	if err != nil {
+		return err
+	}
+
+	// the event is expected before the submission returns, as the observer may see it first
+	submitted := time.Now()
+	for _, watch := range watches {
+		watch.emitted(id, submitted)
+	}
+	if _, err := c.sendClauses([]*transaction.Clause{clause}, signer, txParams{}, "bridged event "+id.Hex()); err != nil {
+		for _, watch := range watches {
+			if _, ok := watch.pending.LoadAndDelete(id); ok {
+				watch.unseen.Add(-1)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// pollBridgedEvents polls the logs API of the observer for the events of the contract, from the block
+// onwards, until stopped.
+func (c *Client) pollBridgedEvents(observer *client.Client, contract common.Address, from uint64, interval time.Duration, watch *bridgeWatch, stop <-chan struct{}) {
+	topic := toolchain.EventTopic()
+	unit := "block"
+	criteria := []client.EventCriteria{{Address: &contract, Topic0: &topic}}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		best, err := observer.BestBlock()
+		if err != nil || best.Number < from {
+			continue
+		}
+		to := best.Number
+		events, err := filterEventsAll(observer, &client.EventFilter{
+			Range:    &client.FilterRange{Unit: &unit, From: &from, To: &to},
+			Criteria: &criteria,
+		})
+		if err != nil {
+			c.log().WithError(err).Warn("failed to poll the logs of the observer")
+			continue
+		}
+		for _, event := range events {
+			if len(event.Topics) > 2 {
+				watch.observe(c, event.Topics[2])
+			}
+		}
+		from = to + 1
+	}
+}
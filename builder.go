@@ -0,0 +1,159 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TxBuilder composes a multi-clause transaction from JS. Every append returns the builder, so calls
+// can be chained, and send signs and sends the clauses as a single transaction.
+type TxBuilder struct {
+	client  *Client
+	clauses []*transaction.Clause
+}
+
+// TxBuilder returns an empty transaction builder.
+func (c *Client) TxBuilder() *TxBuilder {
+	return &TxBuilder{client: c}
+}
+
+// TransferVET appends a clause sending the amount of VET, hex or decimal wei, to the address.
+func (b *TxBuilder) TransferVET(to string, amount string) (*TxBuilder, error) {
+	address, err := parseAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	b.clauses = append(b.clauses, transaction.NewClause(&address).WithValue(value))
+	return b, nil
+}
+
+// TransferVTHO appends a clause sending the amount of VTHO, hex or decimal wei, to the address.
+func (b *TxBuilder) TransferVTHO(to string, amount string) (*TxBuilder, error) {
+	address, err := parseAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	clause, err := builtins.VTHO.Load(b.client.thor).AsClause("transfer", address, value)
+	if err != nil {
+		return nil, err
+	}
+	b.clauses = append(b.clauses, clause)
+	return b, nil
+}
+
+// Call appends a clause calling the method of the contract with the arguments, converted from JS
+// values. The value, hex or decimal wei, is the amount of VET sent with the call.
+func (b *TxBuilder) Call(contract *Contract, method string, args []interface{}, value string) (*TxBuilder, error) {
+	if contract == nil {
+		return nil, errors.New("a contract is required")
+	}
+	clause, err := contract.clause(method, args, value)
+	if err != nil {
+		return nil, err
+	}
+	b.clauses = append(b.clauses, clause)
+	return b, nil
+}
+
+// Clause appends a raw clause, in the same shape as sendDelegated.
+func (b *TxBuilder) Clause(clause map[string]interface{}) (*TxBuilder, error) {
+	clauses, err := parseClauses([]map[string]interface{}{clause})
+	if err != nil {
+		return nil, err
+	}
+	b.clauses = append(b.clauses, clauses...)
+	return b, nil
+}
+
+// Length returns the number of clauses appended so far.
+func (b *TxBuilder) Length() int {
+	return len(b.clauses)
+}
+
+// builderSendOptions configures txBuilder.send.
+type builderSendOptions struct {
+	// Signer is the account index of the sender, random when not set.
+	Signer *int `json:"signer,omitempty"`
+	txParams
+}
+
+// BundleResult is the outcome of a transaction sent by a TxBuilder. Outputs holds, for every clause, the
+// output of the receipt merged with the status of the clause: success, reverted or skipped.
+type BundleResult struct {
+	TxID     string                   `js:"txID"`
+	Reverted bool                     `js:"reverted"`
+	Outputs  []map[string]interface{} `js:"outputs"`
+}
+
+// Send signs the clauses with one account, sends them as a single transaction and waits for its receipt.
+func (b *TxBuilder) Send(options map[string]interface{}) (*BundleResult, error) {
+	var opts builderSendOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if len(b.clauses) == 0 {
+		return nil, errors.New("at least one clause is required")
+	}
+
+	c := b.client
+	signer, err := c.signerOrRandom(opts.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := c.sendClauses(b.clauses, signer, opts.txParams, fmt.Sprintf("bundle of %d clauses", len(b.clauses)))
+	if err != nil {
+		return nil, err
+	}
+	mined, err := c.thor.Transaction(id).Wait()
+	if err != nil {
+		return nil, c.fail(fmt.Errorf("failed to wait for %s: %w", id.Hex(), err))
+	}
+	c.observeReceipt(mined)
+	receipt, err := c.Receipt(id.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	reverted, _ := receipt["reverted"].(bool)
+	receiptOutputs, _ := receipt["outputs"].([]interface{})
+	clauses, _ := receipt["clauses"].([]map[string]interface{})
+
+	outputs := make([]map[string]interface{}, len(clauses))
+	for i, clause := range clauses {
+		output := make(map[string]interface{})
+		if i < len(receiptOutputs) {
+			if fields, ok := receiptOutputs[i].(map[string]interface{}); ok {
+				for key, value := range fields {
+					output[key] = value
+				}
+			}
+		}
+		for key, value := range clause {
+			output[key] = value
+		}
+		outputs[i] = output
+	}
+
+	return &BundleResult{TxID: id.Hex(), Reverted: reverted, Outputs: outputs}, nil
+}
+
+// parseAddress parses a hex address.
+func parseAddress(address string) (common.Address, error) {
+	if !common.IsHexAddress(address) {
+		return common.Address{}, fmt.Errorf("invalid address %q", address)
+	}
+	return common.HexToAddress(address), nil
+}
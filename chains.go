@@ -0,0 +1,126 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"go.k6.io/k6/metrics"
+)
+
+// defaultChainTimeout is how long sendChain waits for the chain to be mined unless a timeout is given.
+const defaultChainTimeout = 5 * time.Minute
+
+// chainOptions configures sendChain.
+type chainOptions struct {
+	// Signer is the account index of the sender of every transaction, random when not set.
+	Signer *int `json:"signer,omitempty"`
+	// Clauses are the clauses of every transaction, a transfer of 0 VET to the sender when empty.
+	Clauses []map[string]interface{} `json:"clauses,omitempty"`
+	// Timeout is how long to wait for the last transaction to be mined, e.g. "2m".
+	Timeout string `json:"timeout,omitempty"`
+	// The params apply to every transaction, the dependsOn only to the first one.
+	txParams
+}
+
+// ChainResult is the outcome of sendChain. Duration is the time from the first submission until the
+// last transaction is mined, in ms, and Blocks is the number of blocks the chain was spread over.
+type ChainResult struct {
+	TxIDs    []string `js:"txIDs"`
+	Duration float64  `js:"duration"`
+	Blocks   int      `js:"blocks"`
+}
+
+// SendChain sends the amount of transactions, each depending on the previous one, all at once, and waits
+// until the last one is mined. The time the node took to mine the whole chain is recorded in
+// vechain_chain_duration, tagged with the length of the chain. A reverted transaction fails the call with
+// the code reverted.
+func (c *Client) SendChain(length int, options map[string]interface{}) (*ChainResult, error) {
+	var opts chainOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if length <= 0 {
+		return nil, errors.New("the chain needs at least one transaction")
+	}
+	timeout := defaultChainTimeout
+	if opts.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(opts.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	signer, err := c.signerOrRandom(opts.Signer)
+	if err != nil {
+		return nil, err
+	}
+	manager, err := c.signer(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	clauses := []*transaction.Clause{transaction.NewClause(ptr(manager.Address()))}
+	if len(opts.Clauses) > 0 {
+		if clauses, err = parseClauses(opts.Clauses); err != nil {
+			return nil, err
+		}
+	}
+
+	started := time.Now()
+	ids := make([]common.Hash, 0, length)
+	params := opts.txParams
+	for i := 0; i < length; i++ {
+		id, err := c.sendClauses(clauses, signer, params, fmt.Sprintf("chain link %d of %d", i+1, length))
+		if err != nil {
+			return nil, c.fail(fmt.Errorf("failed to send link %d of the chain: %w", i+1, err))
+		}
+		ids = append(ids, id)
+		params.DependsOn = id.Hex()
+	}
+
+	last := ids[len(ids)-1]
+	deadline := started.Add(timeout)
+	for {
+		_, err := c.thor.Client.TransactionReceipt(last)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, client.ErrNotFound) {
+			return nil, c.fail(fmt.Errorf("failed to fetch receipt of %s: %w", last.Hex(), err))
+		}
+		if time.Now().After(deadline) {
+			return nil, c.failAs(errorTimeout, fmt.Errorf("the chain was not mined within %s", timeout))
+		}
+		time.Sleep(receiptPollInterval)
+	}
+	duration := time.Since(started)
+
+	result := &ChainResult{TxIDs: make([]string, len(ids)), Duration: metrics.D(duration)}
+	blocks := make(map[common.Hash]struct{})
+	for i, id := range ids {
+		result.TxIDs[i] = id.Hex()
+		receipt, err := c.thor.Client.TransactionReceipt(id)
+		if err != nil {
+			return nil, c.fail(fmt.Errorf("failed to fetch receipt of %s: %w", id.Hex(), err))
+		}
+		blocks[receipt.Meta.BlockID] = struct{}{}
+		c.observeReceipt(receipt)
+		if receipt.Reverted {
+			return nil, revertedError(id)
+		}
+	}
+	result.Blocks = len(blocks)
+
+	c.pushSample(c.metrics.ChainDuration, metrics.D(duration), map[string]string{"length": strconv.Itoa(length)})
+	return result, nil
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
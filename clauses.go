@@ -0,0 +1,79 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// clauseArgs is the JS representation of a clause. A missing to creates a contract, the value is
+// hex when prefixed with 0x and decimal otherwise, and the data is hex.
+type clauseArgs struct {
+	To    *string `json:"to"`
+	Value string  `json:"value,omitempty"`
+	Data  string  `json:"data,omitempty"`
+}
+
+// parseClauses converts the JS representation of clauses into transaction clauses.
+func parseClauses(arguments []map[string]interface{}) ([]*transaction.Clause, error) {
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("at least one clause is required")
+	}
+
+	clauses := make([]*transaction.Clause, len(arguments))
+	for i, argument := range arguments {
+		var args clauseArgs
+		if err := decodeOptions(argument, &args); err != nil {
+			return nil, fmt.Errorf("invalid clause at index %d: %w", i, err)
+		}
+
+		var to *common.Address
+		if args.To != nil {
+			if !common.IsHexAddress(*args.To) {
+				return nil, fmt.Errorf("invalid clause at index %d: invalid to %q", i, *args.To)
+			}
+			address := common.HexToAddress(*args.To)
+			to = &address
+		}
+
+		value, err := parseAmount(args.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid clause at index %d: %w", i, err)
+		}
+
+		data := []byte{}
+		if args.Data != "" && args.Data != "0x" {
+			data, err = hexutil.Decode(args.Data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid clause at index %d: invalid data: %w", i, err)
+			}
+		}
+
+		clauses[i] = transaction.NewClause(to).WithValue(value).WithData(data)
+	}
+	return clauses, nil
+}
+
+// parseAmount parses an amount in wei, hex when prefixed with 0x and decimal otherwise. Empty is zero.
+func parseAmount(amount string) (*big.Int, error) {
+	if amount == "" {
+		return new(big.Int), nil
+	}
+
+	base := 10
+	digits := amount
+	if strings.HasPrefix(amount, "0x") {
+		base = 16
+		digits = strings.TrimPrefix(amount, "0x")
+	}
+
+	value, ok := new(big.Int).SetString(digits, base)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return value, nil
+}
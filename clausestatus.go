@@ -0,0 +1,96 @@
+package xk6_vechain
+
+import (
+	"fmt"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// clauseSucceeded is a clause that executed, and was kept unless the transaction reverted.
+	clauseSucceeded = "success"
+	// clauseReverted is the clause that reverted the transaction.
+	clauseReverted = "reverted"
+	// clauseSkipped is a clause after the reverted one, which never executed.
+	clauseSkipped = "skipped"
+	// clauseUnknown is a clause of a reverted transaction whose replay did not revert, so that the
+	// clause that reverted it is not known.
+	clauseUnknown = "unknown"
+)
+
+// ClauseStatus is the outcome of a single clause of a transaction.
+type ClauseStatus struct {
+	Index   int    `js:"index"`
+	Status  string `js:"status"`
+	VMError string `js:"vmError"`
+}
+
+// clauseStatuses returns the outcome of every clause of the transaction, along with the index of the
+// reverted clause, or -1 when the transaction did not revert or the clause is not known. Receipts of
+// reverted transactions have no outputs, so the clauses are replayed on top of the parent block to find
+// the one that reverted. Transactions earlier in the same block are not replayed, so when the replay
+// does not revert, every clause is reported as unknown.
+func (c *Client) clauseStatuses(id common.Hash, receipt *client.TransactionReceipt) ([]ClauseStatus, int, error) {
+	if !receipt.Reverted {
+		// every clause of a transaction that did not revert has an output
+		statuses := make([]ClauseStatus, len(receipt.Outputs))
+		for i := range statuses {
+			statuses[i] = ClauseStatus{Index: i, Status: clauseSucceeded}
+		}
+		return statuses, -1, nil
+	}
+
+	tx, err := c.thor.Client.Transaction(id)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to fetch transaction %s: %w", id.Hex(), err)
+	}
+
+	block, err := c.thor.Blocks.ByID(receipt.Meta.BlockID)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to fetch block %s: %w", receipt.Meta.BlockID.Hex(), err)
+	}
+
+	clauses := make([]*transaction.Clause, len(tx.Clauses))
+	for i := range tx.Clauses {
+		clauses[i] = &tx.Clauses[i]
+	}
+	origin := tx.Origin
+	gas := tx.Gas
+	inspections, err := c.thor.Client.InspectAt(client.InspectRequest{
+		Clauses: clauses,
+		Caller:  &origin,
+		Gas:     &gas,
+	}, block.ParentID)
+	if err != nil {
+		return nil, -1, fmt.Errorf("failed to replay transaction %s: %w", id.Hex(), err)
+	}
+
+	// the simulation stops at the reverted clause, when it does not revert the failure depends on
+	// the transactions before it in the block, and the reverted clause is not known
+	statuses := make([]ClauseStatus, len(tx.Clauses))
+	failing := -1
+	for i, inspection := range inspections {
+		if inspection.Reverted || inspection.VmError != "" {
+			failing = i
+			statuses[i].VMError = inspection.VmError
+			break
+		}
+	}
+
+	for i := range statuses {
+		statuses[i].Index = i
+		switch {
+		case failing < 0:
+			statuses[i].Status = clauseUnknown
+		case i < failing:
+			statuses[i].Status = clauseSucceeded
+		case i == failing:
+			statuses[i].Status = clauseReverted
+		default:
+			statuses[i].Status = clauseSkipped
+		}
+	}
+	return statuses, failing, nil
+}
@@ -0,0 +1,257 @@
+package xk6_vechain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// solcBinaries is where the solc releases are downloaded from.
+	solcBinaries = "https://binaries.soliditylang.org"
+	// solcDownloadTimeout bounds the download of the release list and of a solc release.
+	solcDownloadTimeout = 5 * time.Minute
+)
+
+var (
+	// solcMu serializes the downloads of solc releases.
+	solcMu sync.Mutex
+	// solcClient downloads the solc releases.
+	solcClient = &http.Client{Timeout: solcDownloadTimeout}
+)
+
+// compileOptions configures compileAndDeploy. The deployment fields are the same as deployContract.
+type compileOptions struct {
+	// SolcVersion, e.g. "0.8.19", downloads and caches that solc release. The solc on the PATH is
+	// used when empty.
+	SolcVersion string `json:"solcVersion,omitempty"`
+	// Contract is the name of the contract to deploy, required when the source defines several.
+	Contract string        `json:"contract,omitempty"`
+	Args     []interface{} `json:"args,omitempty"`
+	Signer   int           `json:"signer,omitempty"`
+	Value    string        `json:"value,omitempty"`
+	Name     string        `json:"name,omitempty"`
+}
+
+// compiledContract is a contract as output by solc --combined-json abi,bin.
+type compiledContract struct {
+	// ABI is an array with recent solc releases, and a JSON encoded string with older ones.
+	ABI json.RawMessage `json:"abi"`
+	Bin string          `json:"bin"`
+}
+
+// CompileAndDeploy compiles the Solidity source with solc and deploys the contract the same way as
+// deployContract. Compilation happens on the load generator, so it is meant for setup.
+func (c *Client) CompileAndDeploy(source string, options map[string]interface{}) (*Contract, error) {
+	var opts compileOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	solc, err := solcPath(opts.SolcVersion)
+	if err != nil {
+		return nil, err
+	}
+	name, compiled, err := compile(solc, source, opts.Contract)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Name == "" {
+		opts.Name = name
+	}
+	return c.deployContract(deployOptions{
+		ABI:      compiled.ABI,
+		Bytecode: "0x" + compiled.Bin,
+		Args:     opts.Args,
+		Signer:   opts.Signer,
+		Value:    opts.Value,
+		Name:     opts.Name,
+	})
+}
+
+// compile compiles the source and returns the named contract, or the only deployable one when the
+// name is empty.
+func compile(solc string, source string, contract string) (string, *compiledContract, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(solc, "--combined-json", "abi,bin", "-")
+	cmd.Stdin = strings.NewReader(source)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", nil, fmt.Errorf("failed to compile: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var output struct {
+		Contracts map[string]*compiledContract `json:"contracts"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return "", nil, fmt.Errorf("failed to parse the solc output: %w", err)
+	}
+
+	// contracts are keyed by <stdin>:Name
+	deployable := make([]string, 0, len(output.Contracts))
+	byName := make(map[string]*compiledContract, len(output.Contracts))
+	for key, compiled := range output.Contracts {
+		name := key[strings.LastIndex(key, ":")+1:]
+		byName[name] = compiled
+		if compiled.Bin != "" {
+			deployable = append(deployable, name)
+		}
+	}
+	sort.Strings(deployable)
+
+	if contract != "" {
+		compiled, ok := byName[contract]
+		if !ok || compiled.Bin == "" {
+			return "", nil, fmt.Errorf("contract %s not found, the source defines %s", contract, strings.Join(deployable, ", "))
+		}
+		return contract, compiled, nil
+	}
+	if len(deployable) != 1 {
+		return "", nil, fmt.Errorf("the source defines %d deployable contracts (%s), set the contract option", len(deployable), strings.Join(deployable, ", "))
+	}
+	return deployable[0], byName[deployable[0]], nil
+}
+
+// solcPath returns the solc binary of the version, downloading it into the user cache directory the
+// first time it is used.
+func solcPath(version string) (string, error) {
+	if version == "" {
+		path, err := exec.LookPath("solc")
+		if err != nil {
+			return "", errors.New("solc not found on the PATH, install it or set the solcVersion option")
+		}
+		return path, nil
+	}
+
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cache, "xk6-vechain", "solc")
+	path := filepath.Join(dir, "solc-v"+strings.TrimPrefix(version, "v"))
+
+	solcMu.Lock()
+	defer solcMu.Unlock()
+
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := downloadSolc(strings.TrimPrefix(version, "v"), path); err != nil {
+		return "", fmt.Errorf("failed to download solc %s: %w", version, err)
+	}
+	return path, nil
+}
+
+// solcBuild is a release in the list.json of a platform.
+type solcBuild struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// SHA256 is the 0x prefixed hex digest of the binary.
+	SHA256 string `json:"sha256"`
+}
+
+// downloadSolc downloads the solc release of the version for the current platform to the path, and
+// checks it against the digest of the release list before using it.
+func downloadSolc(version string, path string) error {
+	platform, err := solcPlatform()
+	if err != nil {
+		return err
+	}
+
+	var list struct {
+		Builds []solcBuild `json:"builds"`
+	}
+	if err := getJSON(fmt.Sprintf("%s/%s/list.json", solcBinaries, platform), &list); err != nil {
+		return err
+	}
+	var build *solcBuild
+	for i := range list.Builds {
+		if list.Builds[i].Version == version {
+			build = &list.Builds[i]
+			break
+		}
+	}
+	if build == nil {
+		return fmt.Errorf("no release %s for %s", version, platform)
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(build.SHA256, "0x"))
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("invalid sha256 %q for release %s", build.SHA256, version)
+	}
+
+	res, err := solcClient.Get(fmt.Sprintf("%s/%s/%s", solcBinaries, platform, build.Path))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	// write to a temporary file first, so that an interrupted download is never used
+	tmp, err := os.CreateTemp(filepath.Dir(path), "download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	digest := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, digest), res.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if !bytes.Equal(digest.Sum(nil), expected) {
+		return fmt.Errorf("sha256 mismatch for %s, got 0x%x, expected %s", build.Path, digest.Sum(nil), build.SHA256)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// solcPlatform returns the directory of the solc releases for the current platform.
+func solcPlatform() (string, error) {
+	switch runtime.GOOS + "/" + runtime.GOARCH {
+	case "linux/amd64":
+		return "linux-amd64", nil
+	case "darwin/amd64", "darwin/arm64":
+		// the macOS releases are universal binaries
+		return "macosx-amd64", nil
+	case "windows/amd64":
+		return "windows-amd64", nil
+	}
+	return "", fmt.Errorf("no solc releases for %s/%s, install solc and leave solcVersion empty", runtime.GOOS, runtime.GOARCH)
+}
+
+// getJSON decodes the JSON served at the URL into v.
+func getJSON(url string, v interface{}) error {
+	res, err := solcClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", res.Status, url)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
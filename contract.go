@@ -0,0 +1,264 @@
+package xk6_vechain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/thor-go-sdk/thorgo/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.k6.io/k6/metrics"
+)
+
+// Contract is a handle to a contract on chain. Handles do not survive being returned from setup,
+// so iterations recreate them from the address and ABI with client.contract.
+type Contract struct {
+	Address string `js:"address"`
+	// TxID is the ID of the deployment transaction, empty unless deployed by the client.
+	TxID string `js:"txID"`
+
+	client   *Client
+	abi      *abi.ABI
+	contract *accounts.Contract
+}
+
+// deployOptions configures deployContract.
+type deployOptions struct {
+	ABI      json.RawMessage `json:"abi"`
+	Bytecode string          `json:"bytecode"`
+	Args     []interface{}   `json:"args,omitempty"`
+	// Signer is the account index of the deployer.
+	Signer int `json:"signer,omitempty"`
+	// Value is the amount of VET sent to the constructor.
+	Value string `json:"value,omitempty"`
+	// Name tags the deploy metrics, defaults to "custom".
+	Name string `json:"name,omitempty"`
+}
+
+// DeployContract deploys a contract from its bytecode and ABI, with the constructor arguments converted
+// from JS values, waits for the receipt, and returns a handle to the deployed contract. The ABI is
+// registered, so the events of the contract are decoded.
+func (c *Client) DeployContract(options map[string]interface{}) (*Contract, error) {
+	var opts deployOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	return c.deployContract(opts)
+}
+
+// deployContract deploys the contract described by the options.
+func (c *Client) deployContract(opts deployOptions) (*Contract, error) {
+	if opts.Name == "" {
+		opts.Name = "custom"
+	}
+
+	contractABI, err := parseABI(opts.ABI)
+	if err != nil {
+		return nil, err
+	}
+	bytecode, err := hexutil.Decode(opts.Bytecode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bytecode: %w", err)
+	}
+	args, err := abiArgs(contractABI.Constructor.Inputs, opts.Args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid constructor arguments: %w", err)
+	}
+	value, err := parseAmount(opts.Value)
+	if err != nil {
+		return nil, err
+	}
+	manager, err := c.signer(opts.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	endSigning := c.beginSigning(opts.Signer)
+	deployed, txID, err := c.thor.Deployer(bytecode, contractABI).WithValue(value).Deploy(manager, args...)
+	endSigning()
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Since(started)
+
+	c.record(txID, "deploy "+opts.Name)
+	abisFor(c.opts.URL).register(deployed.Address, contractABI)
+
+	tags := map[string]string{"contract": opts.Name, "phase": "contract"}
+	c.pushSample(c.metrics.DeployDuration, metrics.D(duration), tags)
+	if receipt, err := c.thor.Transaction(txID).Receipt(); err == nil {
+		c.pushSample(c.metrics.DeployGas, float64(receipt.GasUsed), tags)
+	}
+
+	return &Contract{
+		Address:  deployed.Address.Hex(),
+		TxID:     txID.Hex(),
+		client:   c,
+		abi:      contractABI,
+		contract: deployed,
+	}, nil
+}
+
+// Contract returns a handle to the contract at the address, with the ABI given as a JSON string or
+// array. The ABI is registered, so the events of the contract are decoded.
+func (c *Client) Contract(address string, abiDefinition interface{}) (*Contract, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid address %q", address)
+	}
+
+	raw, err := json.Marshal(abiDefinition)
+	if err != nil {
+		return nil, fmt.Errorf("invalid abi: %w", err)
+	}
+	contractABI, err := parseABI(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := common.HexToAddress(address)
+	abisFor(c.opts.URL).register(addr, contractABI)
+
+	return &Contract{
+		Address:  addr.Hex(),
+		client:   c,
+		abi:      contractABI,
+		contract: c.thor.Account(addr).Contract(contractABI),
+	}, nil
+}
+
+// Call simulates the method with the arguments, converted from JS values, against the best block and
+// returns the decoded return value: undefined without outputs, the value itself with a single
+// output, and an array otherwise. The call is recorded in vechain_req_duration tagged with the method.
+func (ct *Contract) Call(method string, args ...interface{}) (interface{}, error) {
+	abiMethod, packed, err := ct.pack(method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	to := ct.contract.Address
+	response, err := ct.client.thor.Client.Inspect(client.InspectRequest{
+		Clauses: []*transaction.Clause{transaction.NewClause(&to).WithData(packed).WithValue(big.NewInt(0))},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	inspection := response[0]
+	if inspection.Reverted || inspection.VmError != "" {
+		return nil, fmt.Errorf("call to %s reverted: %s", method, inspection.VmError)
+	}
+
+	data, err := hexutil.Decode(inspection.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return data of %s: %w", method, err)
+	}
+	outputs, err := abiMethod.Outputs.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", method, err)
+	}
+
+	switch len(outputs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return jsValue(outputs[0]), nil
+	default:
+		return jsValue(outputs), nil
+	}
+}
+
+// transactOptions configures contract.transact.
+type transactOptions struct {
+	// Signer is the account index of the sender, random when not set.
+	Signer *int `json:"signer,omitempty"`
+	// Value is the amount of VET sent with the call.
+	Value string `json:"value,omitempty"`
+	// Wait waits for the receipt before returning.
+	Wait bool `json:"wait,omitempty"`
+	txParams
+}
+
+// TransactResult is the outcome of contract.transact. The receipt is only set when waited for.
+type TransactResult struct {
+	TxID    string                 `js:"txID"`
+	Receipt map[string]interface{} `js:"receipt"`
+}
+
+// Transact sends a transaction calling the method with the arguments, converted from JS values,
+// signed by the chosen account, and optionally waits for its receipt. A reverted transaction waited for
+// fails the call with the code reverted.
+func (ct *Contract) Transact(method string, args []interface{}, options map[string]interface{}) (*TransactResult, error) {
+	var opts transactOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	clause, err := ct.clause(method, args, opts.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	c := ct.client
+	signer, err := c.signerOrRandom(opts.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := c.sendClauses([]*transaction.Clause{clause}, signer, opts.txParams, fmt.Sprintf("%s on %s", method, ct.Address))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TransactResult{TxID: id.Hex()}
+	if opts.Wait {
+		receipt, err := c.thor.Transaction(id).Wait()
+		if err != nil {
+			return nil, c.fail(fmt.Errorf("failed to wait for %s: %w", id.Hex(), err))
+		}
+		c.observeReceipt(receipt)
+		if receipt.Reverted {
+			return nil, revertedError(id)
+		}
+		if result.Receipt, err = c.decodeReceipt(receipt); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// pack encodes the call of the method with the arguments, converted from JS values.
+func (ct *Contract) pack(method string, args []interface{}) (*abi.Method, []byte, error) {
+	abiMethod, ok := ct.abi.Methods[method]
+	if !ok {
+		return nil, nil, fmt.Errorf("method %s not found in the abi", method)
+	}
+	packArgs, err := abiArgs(abiMethod.Inputs, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid arguments of %s: %w", method, err)
+	}
+	packed, err := ct.abi.Pack(method, packArgs...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+	return &abiMethod, packed, nil
+}
+
+// clause returns the clause calling the method with the arguments and sending the value.
+func (ct *Contract) clause(method string, args []interface{}, amount string) (*transaction.Clause, error) {
+	_, packed, err := ct.pack(method, args)
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	to := ct.contract.Address
+	return transaction.NewClause(&to).WithData(packed).WithValue(value), nil
+}
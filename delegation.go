@@ -0,0 +1,97 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/darrenvechain/thor-go-sdk/txmanager"
+	"github.com/darrenvechain/xk6-vechain/random"
+)
+
+// delegatedOptions configures sendDelegated.
+type delegatedOptions struct {
+	// Signer is the account index of the sender. Defaults to a random account other than the delegator.
+	Signer *int `json:"signer,omitempty"`
+	// DelegatorIndex is the account index of the gas payer.
+	DelegatorIndex *int `json:"delegatorIndex,omitempty"`
+	// Delegators is a set of account indexes the gas payer is picked from at random.
+	Delegators []int `json:"delegators,omitempty"`
+	txParams
+}
+
+// SendDelegated builds a VIP-191 transaction of the clauses, signed by the sender and co-signed by
+// a managed gas payer, sends it, and returns its ID. The sender does not need to hold any VTHO.
+func (c *Client) SendDelegated(clauses []map[string]interface{}, options map[string]interface{}) (string, error) {
+	var opts delegatedOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return "", err
+	}
+
+	txClauses, err := parseClauses(clauses)
+	if err != nil {
+		return "", err
+	}
+
+	var delegatorIndex int
+	switch {
+	case opts.DelegatorIndex != nil:
+		delegatorIndex = *opts.DelegatorIndex
+	case len(opts.Delegators) > 0:
+		delegatorIndex = random.Element(opts.Delegators)
+	default:
+		return "", errors.New("either delegatorIndex or delegators is required")
+	}
+	if _, err := c.signer(delegatorIndex); err != nil {
+		return "", fmt.Errorf("invalid delegator: %w", err)
+	}
+
+	var signer int
+	if opts.Signer != nil {
+		signer = *opts.Signer
+	} else {
+		partition, err := c.partition()
+		if err != nil {
+			return "", err
+		}
+		if signer, err = random.ElementExcluding(partition.indexes(), delegatorIndex); err != nil {
+			return "", errors.New("the VU needs an account other than the delegator to send from")
+		}
+	}
+	manager, err := c.signer(signer)
+	if err != nil {
+		return "", err
+	}
+
+	transactor, err := opts.txParams.apply(c.thor.Transactor(txClauses, manager.Address()).Nonce(random.Nonce()).Delegate())
+	if err != nil {
+		return "", err
+	}
+	tx, err := transactor.Build()
+	if err != nil {
+		return "", err
+	}
+
+	endSigning := c.beginSigning(signer)
+	signature, err := manager.SignTransaction(tx)
+	endSigning()
+	if err != nil {
+		return "", err
+	}
+
+	delegator := txmanager.NewDelegator(c.keys[delegatorIndex])
+	delegatorSignature, err := delegator.Delegate(tx, manager.Address())
+	if err != nil {
+		return "", fmt.Errorf("failed to delegate: %w", err)
+	}
+
+	raw, err := tx.WithSignature(append(signature, delegatorSignature...)).Encoded()
+	if err != nil {
+		return "", err
+	}
+
+	id, err := c.sendInFlight(raw, fmt.Sprintf("delegated %d clauses paid by account %d", len(txClauses), delegatorIndex), signer)
+	if err != nil {
+		return "", err
+	}
+	return id.Hex(), nil
+}
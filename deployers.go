@@ -0,0 +1,93 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/thor-go-sdk/txmanager"
+	"github.com/darrenvechain/xk6-vechain/random"
+	"github.com/darrenvechain/xk6-vechain/toolchain"
+)
+
+// deployerOffset is the child index of the first dedicated deployer account, far beyond the accounts
+// used for load, so that deployers never send anything but their deployment.
+const deployerOffset = 1_000_000
+
+// deployers derives the dedicated deployer accounts of the toolchain contracts from the mnemonic.
+func (c *Client) deployers(amount int) ([]*txmanager.PKManager, error) {
+	if c.wallet == nil {
+		return nil, errors.New("deterministic deployers are derived from the mnemonic, which the client has none of")
+	}
+	deployers := make([]*txmanager.PKManager, amount)
+	for i := range deployers {
+		key := c.wallet.Child(uint32(deployerOffset + i)).MustGetPrivateKey()
+		deployers[i] = txmanager.FromPK(key, c.thor)
+	}
+	return deployers, nil
+}
+
+// fundDeployers sends every deployer that cannot prepay its deployment twice the VTHO it needs, from
+// the first account. Deployers only need VTHO, since the toolchain contract is deployed without value.
+func (c *Client) fundDeployers(deployers []*txmanager.PKManager) error {
+	baseGasPrice, err := c.baseGasPrice()
+	if err != nil {
+		return err
+	}
+	required := new(big.Int).Mul(baseGasPrice, big.NewInt(toolchain.DeterministicGas*2))
+
+	vtho := builtins.VTHO.Load(c.thor)
+	clauses := make([]*transaction.Clause, 0, len(deployers))
+	for _, deployer := range deployers {
+		account, err := c.thor.Account(deployer.Address()).Get()
+		if err != nil {
+			return fmt.Errorf("failed to fetch balance of deployer %s: %w", deployer.Address(), err)
+		}
+		if account.Energy.ToInt().Cmp(required) >= 0 {
+			continue
+		}
+		clause, err := vtho.AsClause("transfer", deployer.Address(), required)
+		if err != nil {
+			return err
+		}
+		clauses = append(clauses, clause)
+	}
+	if len(clauses) == 0 {
+		return nil
+	}
+
+	funder := c.managers[0]
+	endSigning := c.beginSigning(0)
+	tx, err := c.thor.Transactor(clauses, funder.Address()).
+		Nonce(random.Nonce()).
+		Send(funder)
+	endSigning()
+	if err != nil {
+		return fmt.Errorf("failed to fund deployers: %w", err)
+	}
+	c.record(tx.ID(), fmt.Sprintf("fund %d toolchain deployers", len(clauses)))
+
+	receipt, err := tx.Wait()
+	if err != nil {
+		return fmt.Errorf("failed to fund deployers: %w", err)
+	}
+	if receipt.Reverted {
+		return fmt.Errorf("funding deployers reverted in %s", tx.ID().Hex())
+	}
+	return nil
+}
+
+// deployToolchainDeterministic deploys the toolchain contracts from the dedicated deployer accounts,
+// which gives them the same addresses on every run against a fresh node.
+func (c *Client) deployToolchainDeterministic(amount int) ([]*toolchain.Deployment, error) {
+	deployers, err := c.deployers(amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.fundDeployers(deployers); err != nil {
+		return nil, err
+	}
+	return toolchain.DeployDeterministic(c.thor, deployers)
+}
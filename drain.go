@@ -0,0 +1,96 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/metrics"
+)
+
+// drainPollInterval is how often the drain checks whether the submitted transactions were included.
+const drainPollInterval = 100 * time.Millisecond
+
+// errDraining is returned by submissions once the node is draining at the end of the test.
+var errDraining = errors.New("the test is ending, submissions are stopped while draining")
+
+// drainTimeout returns the parsed drainTimeout option, 0 when draining is disabled.
+func (o *options) drainTimeout() (time.Duration, error) {
+	if o.DrainTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(o.DrainTimeout)
+	if err != nil {
+		return 0, err
+	}
+	if timeout < 0 {
+		return 0, errors.New("drainTimeout must not be negative")
+	}
+	return timeout, nil
+}
+
+// testRun identifies a node during a test run, by the TestEnd event that every client of the test receives.
+type testRun struct {
+	url string
+	end *event.Event
+}
+
+// nodeDrain is the end of test drain of a node, run once per test run by the first client of the node.
+type nodeDrain struct {
+	once      sync.Once
+	completed int64
+	abandoned int64
+}
+
+// drains holds the drain of every node of the test runs.
+var drains sync.Map // testRun -> *nodeDrain
+
+// drain stops submissions to the node and waits, up to the drainTimeout, for the transactions already
+// submitted to be included, then lets submissions resume. The first client of the node to end runs the
+// drain while the others wait for it, so that the block poller, which tracks the inclusions, is held
+// until the drain is over. It returns nil when draining is disabled.
+func (c *Client) drain(run testRun) *nodeDrain {
+	timeout, _ := c.opts.drainTimeout()
+	if timeout <= 0 {
+		return nil
+	}
+
+	d, _ := drains.LoadOrStore(run, &nodeDrain{})
+	drain := d.(*nodeDrain)
+	drain.once.Do(func() {
+		stats := statsFor(c.opts.URL)
+		stats.draining.Store(true)
+		defer stats.draining.Store(false)
+
+		pending := stats.pendingTxs.Load()
+		deadline := time.Now().Add(timeout)
+		for stats.pendingTxs.Load() > 0 && time.Now().Before(deadline) {
+			time.Sleep(drainPollInterval)
+		}
+
+		drain.abandoned = stats.pendingTxs.Load()
+		drain.completed = max(pending-drain.abandoned, 0)
+	})
+	return drain
+}
+
+// samples returns the samples of the completed and abandoned transactions, none when draining is disabled.
+func (d *nodeDrain) samples(m vechainMetrics, tags *metrics.TagSet) []metrics.Sample {
+	if d == nil {
+		return nil
+	}
+
+	now := time.Now()
+	sample := func(metric *metrics.Metric, value float64) metrics.Sample {
+		return metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tags},
+			Value:      value,
+			Time:       now,
+		}
+	}
+	return []metrics.Sample{
+		sample(m.DrainCompleted, float64(d.completed)),
+		sample(m.DrainAbandoned, float64(d.abandoned)),
+	}
+}
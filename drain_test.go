@@ -0,0 +1,39 @@
+package xk6_vechain
+
+import (
+	"testing"
+	"time"
+
+	"go.k6.io/k6/event"
+)
+
+func TestDrain(t *testing.T) {
+	url := "http://drain.test:8669"
+	stats := statsFor(url)
+	stats.pendingTxs.Store(3)
+
+	c := &Client{opts: &options{URL: url, DrainTimeout: "200ms"}}
+	run := testRun{url: url, end: &event.Event{Type: event.TestEnd}}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		if !stats.draining.Load() {
+			t.Error("expected submissions to stop while draining")
+		}
+		stats.pendingTxs.Add(-2)
+	}()
+
+	drain := c.drain(run)
+	if drain.completed != 2 || drain.abandoned != 1 {
+		t.Fatalf("expected 2 completed and 1 abandoned transactions, got %d and %d", drain.completed, drain.abandoned)
+	}
+	if stats.draining.Load() {
+		t.Fatal("expected submissions to resume once the drain is over")
+	}
+	if again := c.drain(run); again != drain {
+		t.Fatal("expected the node to be drained once per test run")
+	}
+	if next := c.drain(testRun{url: url, end: &event.Event{Type: event.TestEnd}}); next == drain {
+		t.Fatal("expected the node to be drained again in the next test run")
+	}
+}
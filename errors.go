@@ -0,0 +1,103 @@
+package xk6_vechain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// errorConnection is a request that did not reach the node or got no response.
+	errorConnection = "connection"
+	// errorTimeout is a request or a transaction that took longer than allowed.
+	errorTimeout = "timeout"
+	// errorRejected is a request the node answered with an error, e.g. a transaction refused by the pool.
+	errorRejected = "rejected"
+	// errorReverted is a transaction that was mined but reverted.
+	errorReverted = "reverted"
+	// errorExpired is a transaction that outlived its expiration without being mined.
+	errorExpired = "expired"
+	// errorOther is any other failure.
+	errorOther = "other"
+)
+
+// Error is the error a failed request or transaction is thrown to JS as. The code is the class of the
+// failure, one of connection, timeout, rejected, reverted, expired or other, so that scripts can branch
+// on it: it is e.value.code on a thrown exception, and e.code on a rejected promise. With requestIds,
+// RequestID is the X-Request-Id of the failed request to the node, when a request failed.
+type Error struct {
+	Code      string `js:"code"`
+	Message   string `js:"message"`
+	RequestID string `js:"requestId"`
+
+	err error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// errorClass classifies the error.
+func errorClass(err error) string {
+	var (
+		httpErr *client.HttpError
+		netErr  net.Error
+	)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errorTimeout
+	}
+	if errors.As(err, &httpErr) {
+		return errorRejected
+	}
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return errorTimeout
+		}
+		return errorConnection
+	}
+	return errorOther
+}
+
+// fail classifies the error, counts it in vechain_errors tagged with its class, and returns it as an Error.
+// An error that already is an Error is returned as is, so that it is counted once.
+func (c *Client) fail(err error) error {
+	return c.failAs(errorClass(err), err)
+}
+
+// failAs counts the error in vechain_errors tagged with the class, and returns it as an Error of the class.
+// An error wrapping an Error keeps the class of the wrapped one under its own message, without being
+// counted again, so that wrapping does not lose the code on the JS side.
+func (c *Client) failAs(class string, err error) error {
+	var classified *Error
+	if errors.As(err, &classified) {
+		if classified == err {
+			return err
+		}
+		return &Error{Code: classified.Code, Message: err.Error(), RequestID: classified.RequestID, err: err}
+	}
+	c.pushSample(c.metrics.Errors, 1, map[string]string{"class": class})
+	return &Error{Code: class, Message: err.Error(), RequestID: requestID(err), err: err}
+}
+
+// revertedError returns the Error of a mined transaction that reverted. It is not counted in vechain_errors,
+// observeReceipt already counts reverted transactions there.
+func revertedError(id common.Hash) error {
+	return &Error{Code: errorReverted, Message: fmt.Sprintf("transaction %s reverted", id.Hex())}
+}
+
+// requestID returns the X-Request-Id of the failed request the error wraps, or an empty string.
+func requestID(err error) string {
+	var requestErr *requestError
+	if errors.As(err, &requestErr) {
+		return requestErr.id
+	}
+	return ""
+}
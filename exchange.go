@@ -0,0 +1,371 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	assetVET   = "vet"
+	assetVTHO  = "vtho"
+	assetToken = "token"
+	// defaultHotWallets is the number of hot wallets unless hotWallets is set.
+	defaultHotWallets = 2
+	// defaultDepositAmount is the amount of every deposit unless amount is set, 1 VET or token.
+	defaultDepositAmount = "1000000000000000000"
+	// defaultSweepInterval is how often the hot wallets are swept unless sweepInterval is set.
+	defaultSweepInterval = 30 * time.Second
+	// depositDetectionTimeout is how long the deposits still pending at the end are waited for.
+	depositDetectionTimeout = time.Minute
+)
+
+// exchangeOptions configures runExchangeDeposits.
+type exchangeOptions struct {
+	// TPS is the number of deposits submitted per second.
+	TPS float64 `json:"tps"`
+	// Duration is how long deposits are submitted, e.g. "5m".
+	Duration string `json:"duration"`
+	// HotWallets is the number of accounts receiving the deposits.
+	HotWallets int `json:"hotWallets,omitempty"`
+	// Amount is the amount of every deposit, hex or decimal wei.
+	Amount string `json:"amount,omitempty"`
+	// Token is the address of a VIP-180 token deposited along with VET and VTHO.
+	Token string `json:"token,omitempty"`
+	// SweepInterval is how often the hot wallets consolidate their deposits, e.g. "30s".
+	SweepInterval string `json:"sweepInterval,omitempty"`
+	// MaxInFlight caps the number of deposits in progress at once.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+}
+
+// ExchangeResult is the outcome of runExchangeDeposits.
+type ExchangeResult struct {
+	Deposits     uint64 `js:"deposits"`
+	Failed       uint64 `js:"failed"`
+	Skipped      uint64 `js:"skipped"`
+	Detected     uint64 `js:"detected"`
+	Undetected   uint64 `js:"undetected"`
+	Sweeps       uint64 `js:"sweeps"`
+	SweepsFailed uint64 `js:"sweepsFailed"`
+}
+
+// pendingDeposit is a deposit submitted but not detected yet.
+type pendingDeposit struct {
+	asset     string
+	hotWallet int
+	submitted time.Time
+}
+
+// exchange is the state of a running exchange simulation.
+type exchange struct {
+	client     *Client
+	amount     *big.Int
+	token      *common.Address
+	assets     []string
+	hotWallets int
+
+	pending    sync.Map // common.Hash -> pendingDeposit
+	undetected atomic.Int64
+	detected   atomic.Uint64
+
+	mu       sync.Mutex
+	received map[int]map[string]*big.Int // hot wallet -> asset -> amount detected since the last sweep
+}
+
+// RunExchangeDeposits simulates the traffic of an exchange, blocking until it is over. Account 0 is the
+// cold wallet, the next hotWallets accounts are the hot wallets, and every other account is a customer
+// depositing VET, VTHO and, when token is set, the token to a hot wallet, in turn. Deposits are detected
+// like an exchange would, through the transfer and event subscriptions of the node, and the time from
+// submission to detection is recorded in vechain_deposit_detection_latency tagged with the asset. Every
+// sweepInterval, and once at the end, each hot wallet consolidates the deposits it received into the
+// cold wallet in a single transaction.
+func (c *Client) RunExchangeDeposits(options map[string]interface{}) (*ExchangeResult, error) {
+	var opts exchangeOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.TPS <= 0 {
+		return nil, errors.New("tps must be greater than 0")
+	}
+	duration, err := time.ParseDuration(opts.Duration)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q", opts.Duration)
+	}
+	if opts.HotWallets <= 0 {
+		opts.HotWallets = defaultHotWallets
+	}
+	if len(c.managers) <= opts.HotWallets+1 {
+		return nil, fmt.Errorf("%d accounts are not enough for a cold wallet, %d hot wallets and depositors", len(c.managers), opts.HotWallets)
+	}
+	if opts.Amount == "" {
+		opts.Amount = defaultDepositAmount
+	}
+	amount, err := parseAmount(opts.Amount)
+	if err != nil {
+		return nil, err
+	}
+	sweepInterval := defaultSweepInterval
+	if opts.SweepInterval != "" {
+		if sweepInterval, err = time.ParseDuration(opts.SweepInterval); err != nil || sweepInterval <= 0 {
+			return nil, fmt.Errorf("invalid sweepInterval %q", opts.SweepInterval)
+		}
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultInjectorInFlight
+	}
+
+	ex := &exchange{
+		client:     c,
+		amount:     amount,
+		assets:     []string{assetVET, assetVTHO},
+		hotWallets: opts.HotWallets,
+		received:   make(map[int]map[string]*big.Int),
+	}
+	if opts.Token != "" {
+		token, err := parseAddress(opts.Token)
+		if err != nil {
+			return nil, err
+		}
+		ex.token = &token
+		ex.assets = append(ex.assets, assetToken)
+	}
+
+	conns, err := ex.watch()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	result := &ExchangeResult{}
+	var (
+		wg               sync.WaitGroup
+		deposits, failed atomic.Uint64
+		inFlight         = make(chan struct{}, opts.MaxInFlight)
+		ctx              = c.vu.Context()
+		interval         = time.Duration(float64(time.Second) / opts.TPS)
+		started          = time.Now()
+		end              = started.Add(duration)
+		nextSweep        = started.Add(sweepInterval)
+	)
+
+	for n := 0; ; n++ {
+		next := started.Add(time.Duration(n) * interval)
+		if !next.Before(end) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case <-time.After(time.Until(next)):
+		}
+
+		if !time.Now().Before(nextSweep) {
+			ex.sweep(result)
+			nextSweep = nextSweep.Add(sweepInterval)
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			result.Skipped++
+			continue
+		}
+
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			if err := ex.deposit(n); err != nil {
+				failed.Add(1)
+				return
+			}
+			deposits.Add(1)
+		}(n)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(depositDetectionTimeout)
+	for ex.undetected.Load() > 0 && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(receiptPollInterval):
+		}
+	}
+	ex.sweep(result)
+
+	result.Deposits = deposits.Load()
+	result.Failed = failed.Load()
+	result.Detected = ex.detected.Load()
+	result.Undetected = uint64(ex.undetected.Load())
+	return result, nil
+}
+
+// deposit sends the nth deposit from a customer to a hot wallet.
+func (ex *exchange) deposit(n int) error {
+	c := ex.client
+	customers := len(c.managers) - ex.hotWallets - 1
+	customer := ex.hotWallets + 1 + n%customers
+	hotWallet := 1 + n%ex.hotWallets
+	asset := ex.assets[n%len(ex.assets)]
+
+	clause, err := ex.transferClause(asset, c.managers[hotWallet].Address(), ex.amount)
+	if err != nil {
+		return err
+	}
+
+	submitted := time.Now()
+	id, err := c.sendClauses([]*transaction.Clause{clause}, customer, txParams{}, fmt.Sprintf("%s deposit to hot wallet %d", asset, hotWallet))
+	if err != nil {
+		return err
+	}
+	ex.undetected.Add(1)
+	ex.pending.Store(id, pendingDeposit{asset: asset, hotWallet: hotWallet, submitted: submitted})
+	return nil
+}
+
+// transferClause returns the clause transferring the amount of the asset to the address.
+func (ex *exchange) transferClause(asset string, to common.Address, amount *big.Int) (*transaction.Clause, error) {
+	switch asset {
+	case assetVET:
+		return transaction.NewClause(&to).WithValue(amount), nil
+	case assetVTHO:
+		return builtins.VTHO.Load(ex.client.thor).AsClause("transfer", to, amount)
+	default:
+		// VTHO implements VIP-180, so its ABI encodes transfer for any token
+		data, err := builtins.VTHO.ABI.Pack("transfer", to, amount)
+		if err != nil {
+			return nil, err
+		}
+		return transaction.NewClause(ex.token).WithData(data).WithValue(big.NewInt(0)), nil
+	}
+}
+
+// subscribedMeta is the part of a transfer or event pushed by a subscription that identifies its transaction.
+type subscribedMeta struct {
+	Meta struct {
+		TxID common.Hash `json:"txID"`
+	} `json:"meta"`
+	Obsolete bool `json:"obsolete"`
+}
+
+// watch subscribes to the VET transfers, and to the transfer events of VTHO and of the token, and detects
+// the deposits among them until the returned connections are closed.
+func (ex *exchange) watch() ([]*websocket.Conn, error) {
+	transferTopic := builtins.VTHO.ABI.Events["Transfer"].ID.Hex()
+	paths := []string{
+		"/subscriptions/transfer",
+		"/subscriptions/event?addr=" + builtins.VTHO.Address.Hex() + "&t0=" + transferTopic,
+	}
+	if ex.token != nil {
+		paths = append(paths, "/subscriptions/event?addr="+ex.token.Hex()+"&t0="+transferTopic)
+	}
+
+	conns := make([]*websocket.Conn, 0, len(paths))
+	for _, path := range paths {
+		url := subscriptionURL(ex.client.opts.URL, path)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", url, err)
+		}
+		conns = append(conns, conn)
+
+		go func() {
+			for {
+				var message subscribedMeta
+				if err := conn.ReadJSON(&message); err != nil {
+					return
+				}
+				if !message.Obsolete {
+					ex.detect(message.Meta.TxID)
+				}
+			}
+		}()
+	}
+	return conns, nil
+}
+
+// detect records the detection latency of the deposit made by the transaction, if any, and credits its
+// hot wallet for the next sweep.
+func (ex *exchange) detect(id common.Hash) {
+	value, ok := ex.pending.LoadAndDelete(id)
+	if !ok {
+		return
+	}
+	deposit := value.(pendingDeposit)
+	ex.undetected.Add(-1)
+	ex.detected.Add(1)
+	ex.client.pushSample(ex.client.metrics.DepositLatency, metrics.D(time.Since(deposit.submitted)), map[string]string{
+		"asset": deposit.asset,
+	})
+
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	if ex.received[deposit.hotWallet] == nil {
+		ex.received[deposit.hotWallet] = make(map[string]*big.Int)
+	}
+	if ex.received[deposit.hotWallet][deposit.asset] == nil {
+		ex.received[deposit.hotWallet][deposit.asset] = new(big.Int)
+	}
+	ex.received[deposit.hotWallet][deposit.asset].Add(ex.received[deposit.hotWallet][deposit.asset], ex.amount)
+}
+
+// sweep sends the deposits every hot wallet received since the previous sweep to the cold wallet.
+func (ex *exchange) sweep(result *ExchangeResult) {
+	ex.mu.Lock()
+	received := ex.received
+	ex.received = make(map[int]map[string]*big.Int)
+	ex.mu.Unlock()
+
+	c := ex.client
+	cold := c.managers[0].Address()
+	for hotWallet, amounts := range received {
+		clauses, err := ex.sweepClauses(cold, amounts)
+		if err == nil {
+			_, err = c.sendClauses(clauses, hotWallet, txParams{}, "sweep of hot wallet "+strconv.Itoa(hotWallet))
+		}
+		if err != nil {
+			c.log().WithFields(logrus.Fields{"url": c.opts.URL, "hotWallet": hotWallet}).WithError(err).Warn("sweep failed")
+			result.SweepsFailed++
+			continue
+		}
+		result.Sweeps++
+	}
+}
+
+// sweepClauses returns the clauses transferring the amounts of every asset to the cold wallet.
+func (ex *exchange) sweepClauses(cold common.Address, amounts map[string]*big.Int) ([]*transaction.Clause, error) {
+	clauses := make([]*transaction.Clause, 0, len(amounts))
+	for _, asset := range ex.assets {
+		amount, ok := amounts[asset]
+		if !ok {
+			continue
+		}
+		clause, err := ex.transferClause(asset, cold, amount)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
@@ -0,0 +1,74 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/txmanager"
+)
+
+// exportConcurrency is how many balances exportAccounts fetches at once.
+const exportConcurrency = 16
+
+// ExportedAccount is an account of the client with its balances, in wei as decimal strings.
+type ExportedAccount struct {
+	Index   int    `js:"index"`
+	Address string `js:"address"`
+	VET     string `js:"vet"`
+	VTHO    string `js:"vtho"`
+}
+
+// ExportAccounts returns every account of the client with its current VET and VTHO balances, so that
+// teardown or handleSummary can log which accounts hold leftover funds, e.g. for a cleanup job.
+func (c *Client) ExportAccounts() ([]ExportedAccount, error) {
+	accounts, err := c.balances(0)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]ExportedAccount, len(accounts))
+	for i, account := range accounts {
+		exported[i] = ExportedAccount{
+			Index:   i,
+			Address: c.managers[i].Address().Hex(),
+			VET:     account.Balance.ToInt().String(),
+			VTHO:    account.Energy.ToInt().String(),
+		}
+	}
+	return exported, nil
+}
+
+// balances fetches the accounts of the client from the index on, exportConcurrency at a time.
+func (c *Client) balances(start int) ([]*client.Account, error) {
+	accounts := make([]*client.Account, len(c.managers)-start)
+	errs := make([]error, len(accounts))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, exportConcurrency)
+	for i, manager := range c.managers[start:] {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, manager *txmanager.PKManager) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			address := manager.Address()
+			account, err := c.thor.Account(address).Get()
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to fetch the balances of account %d (%s): %w", start+i, address, err)
+				return
+			}
+			accounts[i] = account
+		}(i, manager)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
@@ -0,0 +1,330 @@
+package xk6_vechain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// faucetAddressPlaceholder is replaced in the faucet request body by the address of the account funded.
+	faucetAddressPlaceholder = "{{address}}"
+	// defaultFaucetBody is the faucet request body unless faucet.body is set.
+	defaultFaucetBody = `{"to":"` + faucetAddressPlaceholder + `"}`
+	// defaultFaucetRetries is how many times a failed faucet request is retried unless faucet.retries is set.
+	defaultFaucetRetries = 3
+	// faucetRetryDelay is the wait before the first retry, doubling with every retry up to maxPollBackoff.
+	faucetRetryDelay = time.Second
+	// faucetTimeout bounds every faucet request.
+	faucetTimeout = 30 * time.Second
+	// defaultFaucetWaitTimeout is how long fundFromFaucet waits for the funds to land unless a timeout is given.
+	defaultFaucetWaitTimeout = 5 * time.Minute
+	// faucetBalanceInterval is how often the balances are checked while waiting for the funds to land.
+	faucetBalanceInterval = 2 * time.Second
+)
+
+// faucetClient sends the faucet requests, which go to a third party rather than the node.
+var faucetClient = &http.Client{Timeout: faucetTimeout}
+
+// faucetOptions configures the faucet fundFromFaucet requests funds from.
+type faucetOptions struct {
+	// URL is the endpoint the requests are sent to.
+	URL string `json:"url,omitempty"`
+	// Method is the HTTP method of the requests, POST by default.
+	Method string `json:"method,omitempty"`
+	// Body is the body of the requests, where {{address}} is replaced by the address of the account
+	// funded, {"to":"{{address}}"} by default.
+	Body string `json:"body,omitempty"`
+	// Headers are set on every request. The Content-Type is application/json unless set.
+	Headers map[string]string `json:"headers,omitempty"`
+	// RateLimit, e.g. "10s", is the least time between two requests to the faucet, across all VUs.
+	RateLimit string `json:"rateLimit,omitempty"`
+	// Retries is how many times a request is retried after a network error, a 429 or a 5xx response,
+	// 3 when not set.
+	Retries int `json:"retries,omitempty"`
+
+	rateLimit time.Duration
+}
+
+// validate checks the faucet options, parses the rate limit and fills in the defaults.
+func (o *faucetOptions) validate() error {
+	parsed, err := url.Parse(o.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("faucet.url must be an http(s) URL, got %q", o.URL)
+	}
+	if o.RateLimit != "" {
+		if o.rateLimit, err = time.ParseDuration(o.RateLimit); err != nil || o.rateLimit < 0 {
+			return fmt.Errorf("invalid faucet.rateLimit %q, expected a duration like \"10s\"", o.RateLimit)
+		}
+	}
+	if o.Retries < 0 {
+		return fmt.Errorf("faucet.retries must not be negative, got %d", o.Retries)
+	}
+	if o.Retries == 0 {
+		o.Retries = defaultFaucetRetries
+	}
+	if o.Method == "" {
+		o.Method = http.MethodPost
+	}
+	if o.Body == "" {
+		o.Body = defaultFaucetBody
+	}
+	return nil
+}
+
+// faucetLimiter spaces the requests to a faucet.
+type faucetLimiter struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+// faucetLimiters holds the faucetLimiter of each faucet URL, so that the rate limit holds across all VUs.
+var faucetLimiters sync.Map
+
+// faucetLimiterFor returns the process-wide limiter of the faucet.
+func faucetLimiterFor(url string) *faucetLimiter {
+	limiter, _ := faucetLimiters.LoadOrStore(url, &faucetLimiter{})
+	return limiter.(*faucetLimiter)
+}
+
+// reserve books the next request slot, and returns how long to wait for it.
+func (l *faucetLimiter) reserve(interval time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(interval)
+	return wait
+}
+
+// faucetError is a response of the faucet outside the 2xx range.
+type faucetError struct {
+	status     int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *faucetError) Error() string {
+	return fmt.Sprintf("faucet responded with %d: %s", e.status, e.body)
+}
+
+// retryable tells whether the request may succeed when sent again.
+func (e *faucetError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= http.StatusInternalServerError
+}
+
+// faucetFundOptions configures fundFromFaucet.
+type faucetFundOptions struct {
+	// Start is the index of the first account funded, 0 when not set.
+	Start int `json:"start,omitempty"`
+	// Accounts is how many accounts are funded from the start index, all the remaining ones when not set.
+	Accounts int `json:"accounts,omitempty"`
+	// Wait waits until the balance of every account the faucet accepted to fund rose, true when not set.
+	Wait *bool `json:"wait,omitempty"`
+	// Timeout is how long to wait for the balances, e.g. "5m", 5 minutes when not set.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// FaucetResult is the outcome of fundFromFaucet. Pending counts the accounts the faucet accepted to
+// fund whose balance had not risen by the timeout, when waiting.
+type FaucetResult struct {
+	Funded  int `js:"funded"`
+	Failed  int `js:"failed"`
+	Pending int `js:"pending"`
+	Retries int `js:"retries"`
+}
+
+// FundFromFaucet requests funds from the faucet option for the accounts of the client, one request
+// per account and within the rate limit of the faucet, retrying failed requests with backoff. It lets
+// scripts fund their accounts on public testnets where none of them holds enough to fund the others.
+// The faucet transfers are mined in the background, so by default it then waits until the balance of
+// every account rose, so that the funds can be spent once it returns. With wait set to false, it
+// returns once every request is answered. The requests are counted in vechain_faucet_requests, tagged
+// with their status, and the share of the accounts funded so far is recorded in vechain_faucet_progress.
+func (c *Client) FundFromFaucet(options map[string]interface{}) (*FaucetResult, error) {
+	if c.opts.Faucet == nil {
+		return nil, errors.New("fundFromFaucet needs the faucet option")
+	}
+	var opts faucetFundOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Start < 0 || opts.Start >= len(c.managers) {
+		return nil, fmt.Errorf("start must be between 0 and %d", len(c.managers)-1)
+	}
+	if opts.Accounts < 0 || opts.Start+opts.Accounts > len(c.managers) {
+		return nil, fmt.Errorf("accounts must be between 1 and %d", len(c.managers)-opts.Start)
+	}
+	if opts.Accounts == 0 {
+		opts.Accounts = len(c.managers) - opts.Start
+	}
+	wait := opts.Wait == nil || *opts.Wait
+	timeout := defaultFaucetWaitTimeout
+	if opts.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(opts.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	// the balances before the requests, to tell when the funds landed
+	var initial []*client.Account
+	if wait {
+		balances, err := c.balances(opts.Start)
+		if err != nil {
+			return nil, err
+		}
+		initial = balances[:opts.Accounts]
+	}
+
+	result := &FaucetResult{}
+	accepted := make([]int, 0, opts.Accounts)
+	for i := opts.Start; i < opts.Start+opts.Accounts; i++ {
+		address := c.managers[i].Address()
+		retries, err := c.requestFaucet(address)
+		result.Retries += retries
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return nil, err
+			}
+			c.log().WithFields(logrus.Fields{"index": i, "address": address.Hex()}).WithError(err).
+				Warn("faucet failed to fund the account")
+			result.Failed++
+			continue
+		}
+		if wait {
+			accepted = append(accepted, i)
+			continue
+		}
+		result.Funded++
+		c.pushSample(c.metrics.FaucetProgress, float64(result.Funded)/float64(opts.Accounts)*100, nil)
+	}
+	if !wait {
+		return result, nil
+	}
+
+	pending, err := c.awaitFaucetFunds(accepted, initial, opts.Start, timeout, func(funded int) {
+		result.Funded = funded
+		c.pushSample(c.metrics.FaucetProgress, float64(funded)/float64(opts.Accounts)*100, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Pending = len(pending)
+	return result, nil
+}
+
+// awaitFaucetFunds polls the balances of the accounts at the indexes until every one rose from its
+// initial balance, the one of the account at start being first, or until the timeout. It calls progress
+// with the number of funded accounts every time it grows, and returns the indexes of the accounts still
+// not funded. The VTHO balance only counts for accounts without VET, as VET generates VTHO.
+func (c *Client) awaitFaucetFunds(indexes []int, initial []*client.Account, start int, timeout time.Duration, progress func(int)) ([]int, error) {
+	deadline := time.Now().Add(timeout)
+	funded := 0
+	for len(indexes) > 0 {
+		remaining := indexes[:0]
+		for _, i := range indexes {
+			account, err := c.thor.Account(c.managers[i].Address()).Get()
+			if err != nil || !fundsLanded(initial[i-start], account) {
+				remaining = append(remaining, i)
+				continue
+			}
+			funded++
+			progress(funded)
+		}
+		indexes = remaining
+
+		if len(indexes) == 0 || time.Now().After(deadline) {
+			break
+		}
+		if !c.sleep(faucetBalanceInterval) {
+			return nil, c.ctx.Err()
+		}
+	}
+	return indexes, nil
+}
+
+// fundsLanded tells whether the account received funds since its initial balances.
+func fundsLanded(initial, current *client.Account) bool {
+	if current.Balance.ToInt().Cmp(initial.Balance.ToInt()) > 0 {
+		return true
+	}
+	return initial.Balance.ToInt().Sign() == 0 && current.Energy.ToInt().Cmp(initial.Energy.ToInt()) > 0
+}
+
+// requestFaucet requests funds for the address, retrying the failures that may be transient, and
+// returns how many times the request was retried.
+func (c *Client) requestFaucet(address common.Address) (int, error) {
+	faucet := c.opts.Faucet
+	limiter := faucetLimiterFor(faucet.URL)
+
+	for attempt := 0; ; attempt++ {
+		if !c.sleep(limiter.reserve(faucet.rateLimit)) {
+			return attempt, c.ctx.Err()
+		}
+		err := faucet.request(c.ctx, address)
+		if err == nil {
+			c.pushSample(c.metrics.FaucetRequests, 1, map[string]string{"status": "ok"})
+			return attempt, nil
+		}
+
+		var responseErr *faucetError
+		if attempt == faucet.Retries || (errors.As(err, &responseErr) && !responseErr.retryable()) {
+			c.pushSample(c.metrics.FaucetRequests, 1, map[string]string{"status": "failed"})
+			return attempt, err
+		}
+		c.pushSample(c.metrics.FaucetRequests, 1, map[string]string{"status": "retried"})
+
+		delay := pollBackoff(faucetRetryDelay, attempt)
+		if responseErr != nil && responseErr.retryAfter > delay {
+			delay = responseErr.retryAfter
+		}
+		if !c.sleep(delay) {
+			return attempt, c.ctx.Err()
+		}
+	}
+}
+
+// request sends a single faucet request for the address.
+func (o *faucetOptions) request(ctx context.Context, address common.Address) error {
+	body := strings.ReplaceAll(o.Body, faucetAddressPlaceholder, address.Hex())
+	req, err := http.NewRequestWithContext(ctx, o.Method, o.URL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range o.Headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := faucetClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	responseErr := &faucetError{status: res.StatusCode, body: strings.TrimSpace(string(message))}
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		responseErr.retryAfter = time.Duration(seconds) * time.Second
+	}
+	return responseErr
+}
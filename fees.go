@@ -0,0 +1,36 @@
+package xk6_vechain
+
+import (
+	"math/big"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+)
+
+// weiPerVTHO converts the amounts of the receipts to VTHO.
+var weiPerVTHO = new(big.Float).SetInt(big.NewInt(1e18))
+
+// observeReceipt accounts for a mined transaction the client waited for: it checks whether it reverted,
+// and adds the VTHO it cost to vechain_vtho_burned and vechain_vtho_rewarded. The fee paid is the sum of
+// the two, the reward being the share of the fee the block proposer receives.
+func (c *Client) observeReceipt(receipt *client.TransactionReceipt) {
+	c.checkReverted(receipt.Reverted)
+	if receipt.Paid == nil {
+		return
+	}
+
+	paid := receipt.Paid.ToInt()
+	reward := new(big.Int)
+	if receipt.Reward != nil {
+		reward = receipt.Reward.ToInt()
+	}
+	burned := new(big.Int).Sub(paid, reward)
+
+	c.pushSample(c.metrics.VTHOBurned, toVTHO(burned), nil)
+	c.pushSample(c.metrics.VTHORewarded, toVTHO(reward), nil)
+}
+
+// toVTHO converts an amount in wei to VTHO.
+func toVTHO(wei *big.Int) float64 {
+	vtho, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), weiPerVTHO).Float64()
+	return vtho
+}
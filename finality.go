@@ -0,0 +1,176 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// finalityPollInterval is how often the finalized block is polled while waiting for finality.
+	finalityPollInterval = time.Second
+	// defaultFinalityTimeout is how long waitForFinalized waits unless a timeout is given.
+	defaultFinalityTimeout = 5 * time.Minute
+)
+
+// finalityOptions configures waitForFinalized.
+type finalityOptions struct {
+	// Timeout is how long to wait, e.g. "2m".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// WaitForFinalized waits until the block, given by number, or the transaction or block, given by ID,
+// is at or behind the finalized checkpoint, and returns the number of its block. A transaction that
+// is not included yet is waited for as well. The wait is recorded in vechain_finality_wait, tagged
+// with the kind of target.
+func (c *Client) WaitForFinalized(target sobek.Value, options map[string]interface{}) (uint64, error) {
+	timeout, err := finalityTimeout(options)
+	if err != nil {
+		return 0, err
+	}
+	return c.waitForFinalized(target.Export(), timeout)
+}
+
+// WaitForFinalizedAsync is the promise-returning variant of WaitForFinalized.
+func (c *Client) WaitForFinalizedAsync(target sobek.Value, options map[string]interface{}) *sobek.Promise {
+	exported := target.Export()
+	timeout, err := finalityTimeout(options)
+
+	return c.async(func() (any, error) {
+		if err != nil {
+			return nil, err
+		}
+		return c.waitForFinalized(exported, timeout)
+	})
+}
+
+// finalityTimeout returns the timeout of the finality options.
+func finalityTimeout(options map[string]interface{}) (time.Duration, error) {
+	var opts finalityOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return 0, err
+	}
+	if opts.Timeout == "" {
+		return defaultFinalityTimeout, nil
+	}
+	timeout, err := time.ParseDuration(opts.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	return timeout, nil
+}
+
+func (c *Client) waitForFinalized(target interface{}, timeout time.Duration) (uint64, error) {
+	started := time.Now()
+	deadline := started.Add(timeout)
+
+	var (
+		number   uint64
+		resolved bool
+		kind     string
+		id       common.Hash
+	)
+
+	switch v := target.(type) {
+	case int64:
+		number, resolved, kind = uint64(v), v >= 0, "block"
+	case float64:
+		number, resolved, kind = uint64(v), v >= 0, "block"
+	case string:
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			number, resolved, kind = n, true, "block"
+		} else if strings.HasPrefix(v, "0x") && len(v) == 66 {
+			id, kind = common.HexToHash(v), "id"
+		} else {
+			return 0, fmt.Errorf("invalid target %q, expected a block number, block ID, or transaction ID", v)
+		}
+	default:
+		return 0, fmt.Errorf("invalid target %v, expected a block number, block ID, or transaction ID", target)
+	}
+	if kind == "block" && !resolved {
+		return 0, fmt.Errorf("invalid block number %v", target)
+	}
+
+	for {
+		if !resolved {
+			n, found, err := c.blockOf(id)
+			if err != nil {
+				return 0, err
+			}
+			if found {
+				number, resolved = n, true
+			}
+		}
+
+		if resolved {
+			finalized, err := c.thor.Blocks.Finalized()
+			if err == nil && finalized.Number >= number {
+				c.pushSample(c.metrics.FinalityWait, metrics.D(time.Since(started)), map[string]string{"target": kind})
+				return number, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("%v was not finalized within %s", target, timeout)
+		}
+		select {
+		case <-c.vu.Context().Done():
+			return 0, c.vu.Context().Err()
+		case <-time.After(finalityPollInterval):
+		}
+	}
+}
+
+// blockOf returns the number of the block including the transaction with the ID, or of the block with the ID.
+func (c *Client) blockOf(id common.Hash) (uint64, bool, error) {
+	receipt, err := c.thor.Client.TransactionReceipt(id)
+	if err == nil {
+		return receipt.Meta.BlockNumber, true, nil
+	}
+	if !errors.Is(err, client.ErrNotFound) {
+		return 0, false, err
+	}
+
+	block, err := c.thor.Blocks.ByID(id)
+	if err == nil {
+		if !block.IsTrunk {
+			return 0, false, fmt.Errorf("block %s is not on the canonical chain", id.Hex())
+		}
+		return block.Number, true, nil
+	}
+	if !errors.Is(err, client.ErrNotFound) {
+		return 0, false, err
+	}
+	return 0, false, nil
+}
+
+// reportFinalityLag fetches the finalized checkpoint and pushes how many blocks the best block is ahead of
+// it in vechain_finality_lag, which keeps growing while finality stalls.
+func (c *Client) reportFinalityLag(best uint64) {
+	finalized, err := c.thor.Blocks.Finalized()
+	if err != nil {
+		c.pushSample(c.metrics.MonitorErrors, 1, map[string]string{"monitor": "finality"})
+		return
+	}
+
+	stats := statsFor(c.opts.URL)
+	for {
+		last := stats.finalized.Load()
+		if finalized.Number <= last || stats.finalized.CompareAndSwap(last, finalized.Number) {
+			break
+		}
+	}
+
+	var lag uint64
+	if latest := stats.finalized.Load(); best > latest {
+		lag = best - latest
+	}
+	c.pushSample(c.metrics.FinalityLag, float64(lag), nil)
+}
@@ -0,0 +1,131 @@
+package xk6_vechain
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/metrics"
+)
+
+// flushTimeout bounds how long the final flush waits for the samples channel.
+const flushTimeout = time.Second
+
+// chainStats are the per node values observed by the block monitor, shared by every client of the node.
+// The totals are emitted by the final flush at the end of the test.
+type chainStats struct {
+	lastBlock   atomic.Uint64
+	observedTxs atomic.Uint64
+	pendingTxs  atomic.Int64
+	includedTxs atomic.Int64  // included transactions waiting for confirmation
+	minedTxs    atomic.Uint64 // transactions whose receipt was checked against the revertRate objective
+	revertedTxs atomic.Uint64 // the reverted ones among them
+	draining    atomic.Bool   // set while the end of test drain runs
+	tps         atomic.Uint64 // math.Float64bits of the TPS of the latest block
+	utilization atomic.Uint64 // math.Float64bits of the gas utilization, in percent, of the latest block
+	finalized   atomic.Uint64 // number of the latest finalized block
+}
+
+var (
+	nodeStats sync.Map // url -> *chainStats
+	flushed   sync.Map // testRun -> struct{}
+)
+
+// statsFor returns the chain stats shared by every client of the node.
+func statsFor(url string) *chainStats {
+	stats, _ := nodeStats.LoadOrStore(url, &chainStats{})
+	return stats.(*chainStats)
+}
+
+// observeBlock records a reported block in the chain stats.
+func (s *chainStats) observeBlock(number uint64, txs int) {
+	for {
+		last := s.lastBlock.Load()
+		if number <= last || s.lastBlock.CompareAndSwap(last, number) {
+			break
+		}
+	}
+	s.observedTxs.Add(uint64(txs))
+}
+
+// setTPS stores the TPS measured for the latest block.
+func (s *chainStats) setTPS(tps float64) {
+	s.tps.Store(math.Float64bits(tps))
+}
+
+// currentTPS returns the TPS measured for the latest block.
+func (s *chainStats) currentTPS() float64 {
+	return math.Float64frombits(s.tps.Load())
+}
+
+// setUtilization stores the gas utilization, in percent, of the latest block.
+func (s *chainStats) setUtilization(utilization float64) {
+	s.utilization.Store(math.Float64bits(utilization))
+}
+
+// currentUtilization returns the gas utilization, in percent, of the latest block.
+func (s *chainStats) currentUtilization() float64 {
+	return math.Float64frombits(s.utilization.Load())
+}
+
+// closeOnTestEnd closes the client once the test ends, after draining the node, emitting the final chain
+// stats while the samples channel is still open, and closing the recording of the record option. Only
+// the first client with a VU state flushes, so every node is reported once per test run.
+func (c *Client) closeOnTestEnd() {
+	events := c.vu.Events().Global
+	id, ch := events.Subscribe(event.TestEnd)
+
+	go func() {
+		evt, ok := <-ch
+		if !ok {
+			return
+		}
+		defer events.Unsubscribe(id)
+		defer evt.Done()
+
+		run := testRun{url: c.opts.URL, end: evt}
+		c.flushChainStats(run, c.drain(run))
+		c.closeRecording()
+		c.Close()
+	}()
+}
+
+// flushChainStats pushes the last block seen, the total observed transactions, and the transactions
+// that were sent but never included, along with the outcome of the drain when drainTimeout is set. The
+// VU context is already done at this point, so the samples are sent to the channel directly.
+func (c *Client) flushChainStats(run testRun, drain *nodeDrain) {
+	state := c.vu.State()
+	if state == nil {
+		return
+	}
+	if _, loaded := flushed.LoadOrStore(run, struct{}{}); loaded {
+		return
+	}
+
+	stats := statsFor(c.opts.URL)
+	tags := state.Tags.GetCurrentValues().Tags.With("url", c.opts.URL).With("node", c.node())
+
+	now := time.Now()
+	sample := func(metric *metrics.Metric, value float64) metrics.Sample {
+		return metrics.Sample{
+			TimeSeries: metrics.TimeSeries{Metric: metric, Tags: tags},
+			Value:      value,
+			Time:       now,
+		}
+	}
+
+	select {
+	case state.Samples <- metrics.ConnectedSamples{
+		Samples: append([]metrics.Sample{
+			sample(c.metrics.LastBlock, float64(stats.lastBlock.Load())),
+			sample(c.metrics.ObservedTxs, float64(stats.observedTxs.Load())),
+			sample(c.metrics.LeftoverTxs, float64(stats.pendingTxs.Load())),
+		}, drain.samples(c.metrics, tags)...),
+		Tags: tags,
+		Time: now,
+	}:
+	case <-time.After(flushTimeout):
+	}
+}
@@ -0,0 +1,426 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/thor-go-sdk/txmanager"
+	"github.com/darrenvechain/xk6-vechain/random"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// fundBatchSize is the maximum number of clauses sent in a single funding transaction.
+	fundBatchSize = 100
+	// tokenDecimals is the number of decimals of VET and VTHO.
+	tokenDecimals = 18
+)
+
+// baseGasPriceKey is the Params key holding the base gas price.
+var baseGasPriceKey = common.BytesToHash([]byte("base-gas-price"))
+
+// fundingPlan holds the clauses each funder has to send, keyed by funder index.
+type fundingPlan struct {
+	start   int
+	clauses map[int][]*transaction.Clause
+	fundees map[int]int
+	// vet and vtho hold the amounts each funder sends in total.
+	vet  map[int]*big.Int
+	vtho map[int]*big.Int
+}
+
+// transfer is the amounts of VET and VTHO sent to an account.
+type transfer struct {
+	to   common.Address
+	vet  *big.Int
+	vtho *big.Int
+}
+
+// transferred returns the amounts of VET and VTHO the funder sends to its fundees.
+func (p *fundingPlan) transferred(funder int) (vet, vtho *big.Int) {
+	return p.vet[funder], p.vtho[funder]
+}
+
+// batches splits the clauses of a funder into transaction sized chunks.
+func (p *fundingPlan) batches(funder int) [][]*transaction.Clause {
+	clauses := p.clauses[funder]
+	batches := make([][]*transaction.Clause, 0, len(clauses)/fundBatchSize+1)
+	for i := 0; i < len(clauses); i += fundBatchSize {
+		end := i + fundBatchSize
+		if end > len(clauses) {
+			end = len(clauses)
+		}
+		batches = append(batches, clauses[i:end])
+	}
+	return batches
+}
+
+// tokenAmounts is an amount of VET and of VTHO in whole tokens as decimals, e.g. "100" or "0.5".
+type tokenAmounts struct {
+	VET  string `json:"vet,omitempty"`
+	VTHO string `json:"vtho,omitempty"`
+}
+
+// wei returns the amounts in wei, zero for the empty ones.
+func (a tokenAmounts) wei() (vet, vtho *big.Int, err error) {
+	if vet, err = parseTokenAmount(a.VET); err != nil {
+		return nil, nil, fmt.Errorf("invalid vet: %w", err)
+	}
+	if vtho, err = parseTokenAmount(a.VTHO); err != nil {
+		return nil, nil, fmt.Errorf("invalid vtho: %w", err)
+	}
+	return vet, vtho, nil
+}
+
+// fundOptions is the options form of fund and estimateFunding.
+type fundOptions struct {
+	// Start is the index of the first account funded, the accounts before it fund the rest.
+	Start int `json:"start"`
+	// The amounts are sent to every account, a token is not sent when its amount is empty.
+	tokenAmounts
+}
+
+// planFunding builds the VET and VTHO transfer clauses for the accounts after the start index.
+// The arguments are either the start index and a hex amount of wei sent in both VET and VTHO, or
+// fundOptions with an amount per token.
+func (c *Client) planFunding(start interface{}, amount string) (*fundingPlan, error) {
+	index, vet, vtho, err := parseFundArgs(start, amount)
+	if err != nil {
+		return nil, err
+	}
+	if index <= 0 {
+		return nil, errors.New("start index must be greater than 0")
+	}
+	if index > len(c.managers) {
+		return nil, errors.New("start index is greater than the number of accounts")
+	}
+
+	transfers := make([]transfer, 0, len(c.managers)-index)
+	for _, manager := range c.managers[index:] {
+		transfers = append(transfers, transfer{to: manager.Address(), vet: vet, vtho: vtho})
+	}
+	return c.planTransfers(transfers, index)
+}
+
+// parseFundArgs returns the start index and the amounts of VET and VTHO in wei of either form of the
+// fund arguments.
+func parseFundArgs(start interface{}, amount string) (int, *big.Int, *big.Int, error) {
+	switch start := start.(type) {
+	case int:
+		value, err := parseHexAmount(amount)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		return start, value, value, nil
+	case int64:
+		return parseFundArgs(int(start), amount)
+	case float64:
+		if start != float64(int(start)) {
+			return 0, nil, nil, fmt.Errorf("invalid start index %v, expected an integer", start)
+		}
+		return parseFundArgs(int(start), amount)
+	case map[string]interface{}:
+		if amount != "" {
+			return 0, nil, nil, errors.New("the amounts of the options form are set as vet and vtho")
+		}
+		var opts fundOptions
+		if err := decodeOptions(start, &opts); err != nil {
+			return 0, nil, nil, err
+		}
+		vet, vtho, err := opts.wei()
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if vet.Sign() == 0 && vtho.Sign() == 0 {
+			return 0, nil, nil, errors.New("nothing to fund, set vet or vtho")
+		}
+		return opts.Start, vet, vtho, nil
+	default:
+		return 0, nil, nil, fmt.Errorf("invalid start %v, expected an index or the funding options", start)
+	}
+}
+
+// parseTokenAmount parses an amount of whole tokens represented as a decimal, e.g. "100" or "0.25",
+// into wei. An empty amount is zero.
+func parseTokenAmount(amount string) (*big.Int, error) {
+	if amount == "" {
+		return new(big.Int), nil
+	}
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if len(fraction) > tokenDecimals {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, tokenDecimals)
+	}
+	digits := whole + fraction + strings.Repeat("0", tokenDecimals-len(fraction))
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok || strings.ContainsAny(digits, "+-") {
+		return nil, fmt.Errorf("invalid amount %q, expected a decimal number of tokens", amount)
+	}
+	return value, nil
+}
+
+// parseHexAmount parses an amount of wei represented as hex, with or without the 0x prefix.
+func parseHexAmount(amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(strings.TrimPrefix(amount, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q, expected a hex value", amount)
+	}
+	return value, nil
+}
+
+// planTransfers builds the clauses of the transfers, spread round-robin over the first funders
+// accounts. A token whose amount is zero is not transferred.
+func (c *Client) planTransfers(transfers []transfer, funders int) (*fundingPlan, error) {
+	plan := &fundingPlan{
+		start:   funders,
+		clauses: make(map[int][]*transaction.Clause),
+		fundees: make(map[int]int),
+		vet:     make(map[int]*big.Int),
+		vtho:    make(map[int]*big.Int),
+	}
+	vthoContract := builtins.VTHO.Load(c.thor)
+
+	for i, transfer := range transfers {
+		funderIndex := i % funders
+		if _, ok := plan.fundees[funderIndex]; !ok {
+			plan.vet[funderIndex] = new(big.Int)
+			plan.vtho[funderIndex] = new(big.Int)
+		}
+
+		if transfer.vet.Sign() > 0 {
+			vetClause := transaction.NewClause(&transfer.to).WithValue(transfer.vet)
+			plan.clauses[funderIndex] = append(plan.clauses[funderIndex], vetClause)
+			plan.vet[funderIndex].Add(plan.vet[funderIndex], transfer.vet)
+		}
+		if transfer.vtho.Sign() > 0 {
+			vthoClause, err := vthoContract.AsClause("transfer", transfer.to, transfer.vtho)
+			if err != nil {
+				return nil, err
+			}
+			plan.clauses[funderIndex] = append(plan.clauses[funderIndex], vthoClause)
+			plan.vtho[funderIndex].Add(plan.vtho[funderIndex], transfer.vtho)
+		}
+		plan.fundees[funderIndex]++
+	}
+
+	return plan, nil
+}
+
+// Fund sends VET and VTHO to the accounts after the index, funded by the accounts before the index.
+// The amount is the amount of VET & VTHO to send, represented as hex. Alternatively the options
+// form, e.g. fund({start: 10, vet: "100", vtho: "5000"}), sets the amount of each token separately
+// in whole tokens.
+// Example: thor solo only funds the first 10 accounts [0-9], so specify 10 as the start index.
+func (c *Client) Fund(start interface{}, amount string) error {
+	plan, err := c.planFunding(start, amount)
+	if err != nil {
+		return err
+	}
+	return c.fund(plan)
+}
+
+// fund executes the funding plan, once the funders are known to hold enough VET and VTHO, and records
+// how long it took in vechain_fund_duration and vechain_fund_rate.
+func (c *Client) fund(plan *fundingPlan) error {
+	if err := c.checkFunderBalances(plan); err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	var wg sync.WaitGroup
+	errs := make([]error, plan.start)
+
+	for i := range plan.clauses {
+		wg.Add(1)
+		manager := c.managers[i]
+		go func(funder int, manager *txmanager.PKManager, batches [][]*transaction.Clause) {
+			defer wg.Done()
+			for _, batch := range batches {
+				batchStarted := time.Now()
+				endSigning := c.beginSigning(funder)
+				tx, err := c.thor.Transactor(batch, manager.Address()).
+					Nonce(random.Nonce()).
+					Send(manager)
+				endSigning()
+				if err != nil {
+					errs[funder] = err
+					return
+				}
+				c.record(tx.ID(), fmt.Sprintf("fund %d clauses from funder %d", len(batch), funder))
+
+				_, err = tx.Wait()
+				if err != nil {
+					errs[funder] = err
+					return
+				}
+
+				c.pushSample(c.metrics.FundBatchDuration, metrics.D(time.Since(batchStarted)), map[string]string{
+					"funder":  strconv.Itoa(funder),
+					"clauses": strconv.Itoa(len(batch)),
+				})
+			}
+		}(i, manager, plan.batches(i))
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	elapsed := time.Since(started)
+	funded := 0
+	for _, fundees := range plan.fundees {
+		funded += fundees
+	}
+	c.pushSample(c.metrics.FundDuration, metrics.D(elapsed), map[string]string{
+		"accounts": strconv.Itoa(funded),
+	})
+	c.pushSample(c.metrics.FundRate, float64(funded)/elapsed.Seconds(), nil)
+
+	return nil
+}
+
+// FundingEstimate is the cost of a funding plan for a single funder.
+// VET and VTHO are the amounts transferred, Fee is the VTHO spent on gas. All amounts are hex encoded.
+type FundingEstimate struct {
+	Funder       string `js:"funder"`
+	FunderIndex  int    `js:"funderIndex"`
+	Accounts     int    `js:"accounts"`
+	Transactions int    `js:"transactions"`
+	VET          string `js:"vet"`
+	VTHO         string `js:"vtho"`
+	Gas          uint64 `js:"gas"`
+	Fee          string `js:"fee"`
+
+	fee *big.Int
+}
+
+// EstimateFunding returns the VET, VTHO, and gas that Fund(start, amount) will consume per funder.
+// Gas is estimated by simulating every funding transaction against the node.
+func (c *Client) EstimateFunding(start interface{}, amount string) ([]FundingEstimate, error) {
+	plan, err := c.planFunding(start, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.estimatePlan(plan)
+}
+
+// estimatePlan simulates every transaction of the plan to compute the cost per funder.
+func (c *Client) estimatePlan(plan *fundingPlan) ([]FundingEstimate, error) {
+	baseGasPrice, err := c.baseGasPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := make([]FundingEstimate, 0, len(plan.clauses))
+	for i := 0; i < plan.start; i++ {
+		if _, ok := plan.clauses[i]; !ok {
+			continue
+		}
+		funder := c.managers[i].Address()
+		batches := plan.batches(i)
+
+		var gas uint64
+		for _, batch := range batches {
+			simulation, err := c.thor.Transactor(batch, funder).Simulate()
+			if err != nil {
+				return nil, fmt.Errorf("failed to simulate funding from %s: %w", funder, err)
+			}
+			if !simulation.IsSuccess() {
+				return nil, fmt.Errorf("funding from %s would fail: %s", funder, simulation.VMError())
+			}
+			gas += simulation.TotalGas()
+		}
+
+		vet, vtho := plan.transferred(i)
+		fee := new(big.Int).Mul(baseGasPrice, new(big.Int).SetUint64(gas))
+
+		estimates = append(estimates, FundingEstimate{
+			Funder:       funder.Hex(),
+			FunderIndex:  i,
+			Accounts:     plan.fundees[i],
+			Transactions: len(batches),
+			VET:          hexutil.EncodeBig(vet),
+			VTHO:         hexutil.EncodeBig(vtho),
+			Gas:          gas,
+			Fee:          hexutil.EncodeBig(fee),
+			fee:          fee,
+		})
+	}
+
+	return estimates, nil
+}
+
+// checkFunderBalances verifies every funder holds enough VET and VTHO to execute the plan.
+// The transferred amounts are checked first, so the fee simulation only runs once the transfers
+// are known to succeed. The returned error lists the shortfall of every funder.
+func (c *Client) checkFunderBalances(plan *fundingPlan) error {
+	balances := make(map[int]*client.Account, len(plan.clauses))
+	shortfalls := make([]string, 0)
+
+	for i := 0; i < plan.start; i++ {
+		if _, ok := plan.clauses[i]; !ok {
+			continue
+		}
+		funder := c.managers[i].Address()
+		account, err := c.thor.Account(funder).Get()
+		if err != nil {
+			return fmt.Errorf("failed to fetch balance of funder %d (%s): %w", i, funder, err)
+		}
+		balances[i] = account
+
+		vet, vtho := plan.transferred(i)
+		shortfalls = appendShortfall(shortfalls, i, funder, "VET", account.Balance.ToInt(), vet)
+		shortfalls = appendShortfall(shortfalls, i, funder, "VTHO", account.Energy.ToInt(), vtho)
+	}
+
+	if len(shortfalls) == 0 {
+		estimates, err := c.estimatePlan(plan)
+		if err != nil {
+			return err
+		}
+		for _, estimate := range estimates {
+			_, vtho := plan.transferred(estimate.FunderIndex)
+			required := new(big.Int).Add(vtho, estimate.fee)
+			energy := balances[estimate.FunderIndex].Energy.ToInt()
+			funder := c.managers[estimate.FunderIndex].Address()
+			shortfalls = appendShortfall(shortfalls, estimate.FunderIndex, funder, "VTHO (incl. fees)", energy, required)
+		}
+	}
+
+	if len(shortfalls) > 0 {
+		return fmt.Errorf("insufficient funder balances: %s", strings.Join(shortfalls, "; "))
+	}
+
+	return nil
+}
+
+// appendShortfall appends a description of the shortfall when the balance is below the required amount.
+func appendShortfall(shortfalls []string, index int, funder common.Address, token string, balance, required *big.Int) []string {
+	if balance.Cmp(required) >= 0 {
+		return shortfalls
+	}
+	missing := new(big.Int).Sub(required, balance)
+	return append(shortfalls, fmt.Sprintf("funder %d (%s) is short %s %s", index, funder, hexutil.EncodeBig(missing), token))
+}
+
+// baseGasPrice reads the current base gas price from the Params built-in contract.
+func (c *Client) baseGasPrice() (*big.Int, error) {
+	var price *big.Int
+	if err := builtins.Params.Load(c.thor).Call("get", &price, baseGasPriceKey); err != nil {
+		return nil, fmt.Errorf("failed to read base gas price: %w", err)
+	}
+	return price, nil
+}
@@ -0,0 +1,49 @@
+package xk6_vechain
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		wei    string
+		err    string
+	}{
+		{amount: "", wei: "0"},
+		{amount: "0", wei: "0"},
+		{amount: "100", wei: "100000000000000000000"},
+		{amount: "0.25", wei: "250000000000000000"},
+		{amount: ".5", wei: "500000000000000000"},
+		{amount: "1.", wei: "1000000000000000000"},
+		{amount: "0.000000000000000001", wei: "1"},
+		{amount: "0.0000000000000000001", err: `amount "0.0000000000000000001" has more than 18 decimals`},
+		{amount: "-1", err: `invalid amount "-1", expected a decimal number of tokens`},
+		{amount: "+1", err: `invalid amount "+1", expected a decimal number of tokens`},
+		{amount: "1.-5", err: `invalid amount "1.-5", expected a decimal number of tokens`},
+		{amount: "1.2.3", err: `invalid amount "1.2.3", expected a decimal number of tokens`},
+		{amount: "0x10", err: `invalid amount "0x10", expected a decimal number of tokens`},
+		{amount: "1e18", err: `invalid amount "1e18", expected a decimal number of tokens`},
+		{amount: "ten", err: `invalid amount "ten", expected a decimal number of tokens`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			wei, err := parseTokenAmount(tt.amount)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected the error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected, _ := new(big.Int).SetString(tt.wei, 10)
+			if wei.Cmp(expected) != 0 {
+				t.Fatalf("expected %s wei, got %s", expected, wei)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/xk6-vechain/random"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// txGas is the intrinsic gas of a transaction, and clauseGas that of each of its clauses.
+	txGas     = 5000
+	clauseGas = 16000
+	// defaultSweepPerTarget is the number of transactions sent for every gas target unless perTarget is set.
+	defaultSweepPerTarget = 10
+)
+
+// defaultGasTargets is the ladder of gas targets swept unless targets are given, topped with a
+// transaction filling the best block.
+var defaultGasTargets = []uint64{21_000, 100_000, 500_000, 1_000_000, 5_000_000}
+
+// gasSweepOptions configures gasSweep.
+type gasSweepOptions struct {
+	// Targets are the gas of the transactions of each step, from 21000 up to the block gas limit.
+	Targets []uint64 `json:"targets,omitempty"`
+	// PerTarget is the number of transactions sent for each target, at once.
+	PerTarget int `json:"perTarget,omitempty"`
+	// Timeout is how long each transaction is waited for, e.g. "1m".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// GasSweepStep is the outcome of a gas target of gasSweep. Gas is the gas of its transactions, the
+// closest to the target a number of clauses reaches, and TimeToMine the average over the mined ones, in ms.
+type GasSweepStep struct {
+	Target     uint64  `js:"target"`
+	Gas        uint64  `js:"gas"`
+	Clauses    int     `js:"clauses"`
+	Mined      int     `js:"mined"`
+	Failed     int     `js:"failed"`
+	TimeToMine float64 `js:"timeToMine"`
+}
+
+// GasSweep measures the inclusion latency as a function of the transaction weight, blocking until it is
+// over. For each gas target in turn, perTarget transactions weighing the target are sent at once from
+// random accounts, and waited for. The weight comes from empty VET transfers to random addresses, which
+// cost no more than their intrinsic gas, and their time to mine is recorded in vechain_time_to_mine
+// tagged with the target in gas_bucket.
+func (c *Client) GasSweep(options map[string]interface{}) ([]GasSweepStep, error) {
+	var opts gasSweepOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.PerTarget <= 0 {
+		opts.PerTarget = defaultSweepPerTarget
+	}
+	timeout := defaultMineTimeout
+	if opts.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(opts.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	best, err := c.thor.Blocks.Best()
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Targets) == 0 {
+		opts.Targets = append(append([]uint64(nil), defaultGasTargets...), best.GasLimit)
+	}
+	for _, target := range opts.Targets {
+		if target < txGas+clauseGas {
+			return nil, fmt.Errorf("invalid target %d, a transaction takes at least %d gas", target, txGas+clauseGas)
+		}
+		if target > best.GasLimit {
+			return nil, fmt.Errorf("invalid target %d, the block gas limit is %d", target, best.GasLimit)
+		}
+	}
+
+	steps := make([]GasSweepStep, 0, len(opts.Targets))
+	for _, target := range opts.Targets {
+		step, err := c.sweepGasTarget(target, opts.PerTarget, timeout)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// sweepGasTarget sends and waits for the transactions of a gas target.
+func (c *Client) sweepGasTarget(target uint64, count int, timeout time.Duration) (GasSweepStep, error) {
+	clauses := int((target - txGas) / clauseGas)
+	step := GasSweepStep{Target: target, Gas: txGas + uint64(clauses)*clauseGas, Clauses: clauses}
+	tags := map[string]string{"gas_bucket": strconv.FormatUint(target, 10)}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		elapsed float64
+	)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mined, err := c.sendWeighted(clauses, step.Gas, timeout, tags)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				step.Failed++
+				return
+			}
+			step.Mined++
+			elapsed += mined.TimeToMine
+		}()
+	}
+	wg.Wait()
+
+	if c.vu.Context().Err() != nil {
+		return step, c.vu.Context().Err()
+	}
+	if step.Mined > 0 {
+		step.TimeToMine = elapsed / float64(step.Mined)
+	}
+	return step, nil
+}
+
+// sendWeighted sends a transaction of empty transfers weighing the gas, and waits for it.
+func (c *Client) sendWeighted(clauses int, gas uint64, timeout time.Duration, tags map[string]string) (*MinedTx, error) {
+	if clauses <= 0 {
+		return nil, errors.New("at least one clause is required")
+	}
+	transfers := make([]*transaction.Clause, clauses)
+	for i := range transfers {
+		to := common.BytesToAddress(random.Bytes(20))
+		transfers[i] = transaction.NewClause(&to).WithValue(new(big.Int))
+	}
+
+	signer, err := c.randomSigner()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.signClauses(transfers, signer, txParams{Gas: gas})
+	if err != nil {
+		return nil, err
+	}
+	return c.sendAndWait(raw, timeout, tags)
+}
@@ -0,0 +1,114 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// inFlightBlock makes a submission wait for a free slot when the sender is at maxInFlight.
+	inFlightBlock = "block"
+	// inFlightError makes a submission fail when the sender is at maxInFlight.
+	inFlightError = "error"
+	// inFlightPollInterval is how often a blocked submission checks for a free slot.
+	inFlightPollInterval = 10 * time.Millisecond
+	// inFlightTimeout frees the slot of a transaction that was never seen in a block, e.g. because it expired.
+	inFlightTimeout = 5 * time.Minute
+)
+
+// inFlightLimiter caps the number of submitted but unmined transactions per sender.
+// It is shared by all VUs, since every VU signs with the same accounts.
+type inFlightLimiter struct {
+	mu    sync.Mutex
+	slots map[common.Address][]*inFlightSlot // occupied slots of each sender, oldest first
+}
+
+// inFlightSlot is a slot occupied by a transaction. It is freed once, either when the transaction is
+// mined, when its submission fails or the tracker evicts it, or by itself after inFlightTimeout.
+type inFlightSlot struct {
+	sender   common.Address
+	acquired time.Time
+	freed    bool // guarded by the mutex of the limiter
+}
+
+// limiters holds the inFlightLimiter of each node URL.
+var limiters sync.Map
+
+// limiterFor returns the process-wide limiter of the node.
+func limiterFor(url string) *inFlightLimiter {
+	limiter, _ := limiters.LoadOrStore(url, &inFlightLimiter{slots: make(map[common.Address][]*inFlightSlot)})
+	return limiter.(*inFlightLimiter)
+}
+
+// tryAcquire occupies a slot of the sender if fewer than max are occupied, and returns nil otherwise.
+func (l *inFlightLimiter) tryAcquire(sender common.Address, max int) *inFlightSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots := l.slots[sender]
+	for len(slots) > 0 && time.Since(slots[0].acquired) > inFlightTimeout {
+		slots[0].freed = true
+		slots = slots[1:]
+	}
+	if len(slots) >= max {
+		l.slots[sender] = slots
+		return nil
+	}
+	slot := &inFlightSlot{sender: sender, acquired: time.Now()}
+	l.slots[sender] = append(slots, slot)
+	return slot
+}
+
+// release frees the slot, unless it was already freed.
+func (l *inFlightLimiter) release(slot *inFlightSlot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if slot.freed {
+		return
+	}
+	slot.freed = true
+
+	slots := l.slots[slot.sender]
+	for i, s := range slots {
+		if s == slot {
+			l.slots[slot.sender] = append(slots[:i:i], slots[i+1:]...)
+			break
+		}
+	}
+}
+
+// acquireInFlight occupies an in-flight slot of the signer, waiting or failing according to
+// inFlightMode when the signer already has maxInFlight transactions pending. It returns a nil slot
+// unless maxInFlight is set.
+func (c *Client) acquireInFlight(signer int) (*inFlightSlot, error) {
+	if c.opts.MaxInFlight <= 0 || signer < 0 {
+		return nil, nil
+	}
+
+	sender := c.managers[signer].Address()
+	limiter := limiterFor(c.opts.URL)
+	for {
+		if slot := limiter.tryAcquire(sender, c.opts.MaxInFlight); slot != nil {
+			return slot, nil
+		}
+		if c.opts.InFlightMode == inFlightError {
+			return nil, fmt.Errorf("account %d (%s) has %d transactions in flight", signer, sender, c.opts.MaxInFlight)
+		}
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-time.After(inFlightPollInterval):
+		}
+	}
+}
+
+// releaseInFlight frees the in-flight slot, if any.
+func (c *Client) releaseInFlight(slot *inFlightSlot) {
+	if slot == nil {
+		return
+	}
+	limiterFor(c.opts.URL).release(slot)
+}
@@ -0,0 +1,91 @@
+package xk6_vechain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestInFlightLimiter(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	other := common.HexToAddress("0x2")
+
+	tests := []struct {
+		name string
+		// run occupies and frees slots of the limiter, and returns whether the sender can still acquire
+		run      func(l *inFlightLimiter) bool
+		acquired bool
+	}{
+		{
+			name:     "below max",
+			run:      func(l *inFlightLimiter) bool { return l.tryAcquire(sender, 2) != nil },
+			acquired: true,
+		},
+		{
+			name: "at max",
+			run: func(l *inFlightLimiter) bool {
+				l.tryAcquire(sender, 2)
+				l.tryAcquire(sender, 2)
+				return l.tryAcquire(sender, 2) != nil
+			},
+			acquired: false,
+		},
+		{
+			name: "other sender at max",
+			run: func(l *inFlightLimiter) bool {
+				l.tryAcquire(other, 1)
+				return l.tryAcquire(sender, 1) != nil
+			},
+			acquired: true,
+		},
+		{
+			name: "released",
+			run: func(l *inFlightLimiter) bool {
+				l.release(l.tryAcquire(sender, 1))
+				return l.tryAcquire(sender, 1) != nil
+			},
+			acquired: true,
+		},
+		{
+			name: "released twice",
+			run: func(l *inFlightLimiter) bool {
+				first := l.tryAcquire(sender, 2)
+				l.tryAcquire(sender, 2)
+				l.release(first)
+				l.release(first)
+				l.tryAcquire(sender, 2)
+				return l.tryAcquire(sender, 2) != nil
+			},
+			acquired: false,
+		},
+		{
+			name: "expired",
+			run: func(l *inFlightLimiter) bool {
+				l.tryAcquire(sender, 1).acquired = time.Now().Add(-inFlightTimeout - time.Second)
+				return l.tryAcquire(sender, 1) != nil
+			},
+			acquired: true,
+		},
+		{
+			name: "released once expired",
+			run: func(l *inFlightLimiter) bool {
+				expired := l.tryAcquire(sender, 1)
+				expired.acquired = time.Now().Add(-inFlightTimeout - time.Second)
+				l.tryAcquire(sender, 1)
+				l.release(expired)
+				return l.tryAcquire(sender, 1) != nil
+			},
+			acquired: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &inFlightLimiter{slots: make(map[common.Address][]*inFlightSlot)}
+			if acquired := tt.run(l); acquired != tt.acquired {
+				t.Fatalf("expected the slot to be acquired: %v, got %v", tt.acquired, acquired)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultInjectorRate is the initial submission rate, in transactions per second.
+	defaultInjectorRate = 10
+	// defaultInjectorMaxRate caps the submission rate, in transactions per second.
+	defaultInjectorMaxRate = 1000
+	// defaultInjectorInFlight caps the number of submissions in progress at once.
+	defaultInjectorInFlight = 100
+	// injectorDecrease is the multiplicative decrease applied when the chain is saturated.
+	injectorDecrease = 0.8
+	// injectorIncrease is the additive increase, relative to the current rate, applied otherwise.
+	injectorIncrease = 0.1
+)
+
+// injectorOptions configures the adaptive injector.
+type injectorOptions struct {
+	// Contract is the toolchain contract the injected transactions call.
+	Contract string `json:"contract"`
+	// TargetUtilization is the block gas utilization, in percent, the injector converges on.
+	TargetUtilization float64 `json:"targetUtilization,omitempty"`
+	// MaxMempool is the number of sent but not yet included transactions at which the injector backs off.
+	MaxMempool int64 `json:"maxMempool,omitempty"`
+	// InitialRate is the submission rate, in transactions per second, the injector starts at.
+	InitialRate float64 `json:"initialRate,omitempty"`
+	// MaxRate caps the submission rate, in transactions per second.
+	MaxRate float64 `json:"maxRate,omitempty"`
+	// MaxInFlight caps the number of submissions in progress at once.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+}
+
+// Injector submits toolchain transactions from Go at a rate adjusted after every block.
+// While the latest block is below the target utilization and the mempool is below its limit the
+// rate increases additively, otherwise it decreases multiplicatively, converging on the
+// sustainable maximum of the node.
+type Injector struct {
+	client *Client
+	opts   injectorOptions
+
+	rate      atomic.Uint64 // math.Float64bits of the rate in transactions per second
+	paused    atomic.Bool
+	sent      atomic.Uint64
+	failed    atomic.Uint64
+	inFlight  chan struct{}
+	stop      chan struct{}
+	stopOnce  sync.Once
+	lastBlock uint64
+}
+
+// StartAdaptiveInjector starts an adaptive injector. Either targetUtilization or maxMempool must be set.
+func (c *Client) StartAdaptiveInjector(argument map[string]interface{}) (*Injector, error) {
+	var opts injectorOptions
+	if err := decodeOptions(argument, &opts); err != nil {
+		return nil, err
+	}
+
+	if opts.Contract == "" {
+		return nil, errors.New("contract is required")
+	}
+	if opts.TargetUtilization <= 0 && opts.MaxMempool <= 0 {
+		return nil, errors.New("either targetUtilization or maxMempool is required")
+	}
+	if opts.InitialRate <= 0 {
+		opts.InitialRate = defaultInjectorRate
+	}
+	if opts.MaxRate <= 0 {
+		opts.MaxRate = defaultInjectorMaxRate
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultInjectorInFlight
+	}
+
+	injector := &Injector{
+		client:   c,
+		opts:     opts,
+		inFlight: make(chan struct{}, opts.MaxInFlight),
+		stop:     make(chan struct{}),
+	}
+	injector.setRate(opts.InitialRate)
+
+	go injector.control()
+	go injector.inject()
+
+	return injector, nil
+}
+
+// Stop stops the injector. Submissions already in progress are not cancelled.
+func (i *Injector) Stop() {
+	i.stopOnce.Do(func() {
+		close(i.stop)
+	})
+}
+
+// Pause suspends the submissions and the rate adjustments until resume is called, e.g. while a node is
+// restarted. Submissions already in progress are not cancelled.
+func (i *Injector) Pause() {
+	i.paused.Store(true)
+}
+
+// Resume resumes the submissions at the rate the injector was paused at, or the one set since.
+func (i *Injector) Resume() {
+	i.paused.Store(false)
+}
+
+// Paused returns true while the injector is paused.
+func (i *Injector) Paused() bool {
+	return i.paused.Load()
+}
+
+// SetRate sets the submission rate, in transactions per second, capped by maxRate. The injector keeps
+// adjusting the rate from there after every block.
+func (i *Injector) SetRate(tps float64) error {
+	if tps <= 0 {
+		return errors.New("rate must be greater than 0")
+	}
+	i.setRate(tps)
+	i.client.pushSample(i.client.metrics.InjectorRate, i.Rate(), nil)
+	return nil
+}
+
+// Rate returns the current submission rate in transactions per second.
+func (i *Injector) Rate() float64 {
+	return math.Float64frombits(i.rate.Load())
+}
+
+// Sent returns the number of transactions submitted successfully.
+func (i *Injector) Sent() uint64 {
+	return i.sent.Load()
+}
+
+// Failed returns the number of transactions that failed to be built or submitted.
+func (i *Injector) Failed() uint64 {
+	return i.failed.Load()
+}
+
+func (i *Injector) setRate(rate float64) {
+	i.rate.Store(math.Float64bits(math.Max(1, math.Min(rate, i.opts.MaxRate))))
+}
+
+// done returns true once the injector is stopped or the VU is done.
+func (i *Injector) done() bool {
+	select {
+	case <-i.stop:
+		return true
+	case <-i.client.vu.Context().Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// saturated reports whether the latest block or the mempool exceeds the configured limits.
+func (i *Injector) saturated(stats *chainStats) bool {
+	if i.opts.TargetUtilization > 0 && stats.currentUtilization() >= i.opts.TargetUtilization {
+		return true
+	}
+	return i.opts.MaxMempool > 0 && stats.pendingTxs.Load() >= i.opts.MaxMempool
+}
+
+// control adjusts the rate once for every new block observed by the block monitor.
+func (i *Injector) control() {
+	stats := statsFor(i.client.opts.URL)
+	i.lastBlock = stats.lastBlock.Load()
+
+	for !i.done() {
+		time.Sleep(i.client.pollInterval())
+
+		block := stats.lastBlock.Load()
+		if block <= i.lastBlock {
+			continue
+		}
+		i.lastBlock = block
+		if i.paused.Load() {
+			continue
+		}
+
+		rate := i.Rate()
+		if i.saturated(stats) {
+			i.setRate(rate * injectorDecrease)
+		} else {
+			i.setRate(rate + math.Max(1, rate*injectorIncrease))
+		}
+		i.client.pushSample(i.client.metrics.InjectorRate, i.Rate(), nil)
+	}
+}
+
+// inject submits transactions at the current rate, skipping a submission when too many are in flight.
+func (i *Injector) inject() {
+	for !i.done() {
+		time.Sleep(time.Duration(float64(time.Second) / i.Rate()))
+		if i.paused.Load() {
+			continue
+		}
+
+		select {
+		case i.inFlight <- struct{}{}:
+		default:
+			continue
+		}
+
+		go func() {
+			defer func() { <-i.inFlight }()
+			if _, err := i.client.SendToolchainTransaction(i.opts.Contract, nil); err != nil {
+				i.failed.Add(1)
+				return
+			}
+			i.sent.Add(1)
+		}()
+	}
+}
@@ -0,0 +1,76 @@
+package xk6_vechain
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// signerUse is how an account is being used for signing.
+type signerUse struct {
+	active int    // signings in progress
+	vu     uint64 // ID of the last VU that signed with the account
+}
+
+// signerUses tracks the signing activity of every account, across all VUs, for verifySignerIsolation.
+var (
+	signerUsesMu sync.Mutex
+	signerUses   = make(map[common.Address]*signerUse)
+	// signerWarnings holds the account and kind of every conflict already logged, so that each is logged once.
+	signerWarnings sync.Map
+)
+
+// beginSigning marks the account at the signer index as signing and returns the function that ends it.
+// With verifySignerIsolation, signing from an account that is already signing, or that another VU signed
+// with, is logged once and counted in vechain_signer_conflicts tagged with the kind of conflict.
+func (c *Client) beginSigning(signer int) func() {
+	if !c.opts.VerifySignerIsolation || signer < 0 || signer >= len(c.managers) {
+		return func() {}
+	}
+
+	var vu uint64
+	if state := c.vu.State(); state != nil {
+		vu = state.VUID
+	}
+	address := c.managers[signer].Address()
+
+	signerUsesMu.Lock()
+	use, ok := signerUses[address]
+	if !ok {
+		use = &signerUse{vu: vu}
+		signerUses[address] = use
+	}
+	concurrent := use.active > 0
+	shared := use.vu != vu
+	use.active++
+	use.vu = vu
+	signerUsesMu.Unlock()
+
+	if concurrent {
+		c.signerConflict(signer, address, "concurrent")
+	}
+	if shared {
+		c.signerConflict(signer, address, "shared")
+	}
+
+	return func() {
+		signerUsesMu.Lock()
+		use.active--
+		signerUsesMu.Unlock()
+	}
+}
+
+// signerConflict reports a signer isolation conflict.
+func (c *Client) signerConflict(signer int, address common.Address, kind string) {
+	c.pushSample(c.metrics.SignerConflicts, 1, map[string]string{
+		"account": strconv.Itoa(signer),
+		"kind":    kind,
+	})
+
+	if _, logged := signerWarnings.LoadOrStore(address.Hex()+kind, struct{}{}); !logged {
+		c.log().WithFields(logrus.Fields{"account": signer, "address": address, "kind": kind}).
+			Warn("signer isolation violated, partition the accounts between VUs")
+	}
+}
@@ -0,0 +1,109 @@
+package xk6_vechain
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/hdwallet"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// decryptedKeystore is the keys of a keystore directory, along with the hash of the password that
+// decrypted them, so that the password itself is not kept.
+type decryptedKeystore struct {
+	passwordHash [sha256.Size]byte
+	keys         []*ecdsa.PrivateKey
+}
+
+// keystores holds the decrypted keys of each keystore directory, since decrypting is deliberately slow
+// and every VU would otherwise decrypt the same files.
+var keystores sync.Map // path -> *decryptedKeystore
+
+// keystoreKeys decrypts the keystore files of the directory with the password, and returns their keys
+// ordered by file name. Hidden files and subdirectories are skipped.
+func keystoreKeys(dir, password string) ([]*ecdsa.PrivateKey, error) {
+	passwordHash := sha256.Sum256([]byte(password))
+	if cached, ok := keystores.Load(dir); ok && cached.(*decryptedKeystore).passwordHash == passwordHash {
+		return cached.(*decryptedKeystore).keys, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore %s: %w", dir, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && entry.Name()[0] != '.' {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("keystore %s holds no key files", dir)
+	}
+
+	keys := make([]*ecdsa.PrivateKey, len(names))
+	for i, name := range names {
+		encrypted, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file %s: %w", name, err)
+		}
+		key, err := keystore.DecryptKey(encrypted, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key file %s: %w", name, err)
+		}
+		keys[i] = key.PrivateKey
+	}
+
+	keystores.Store(dir, &decryptedKeystore{passwordHash: passwordHash, keys: keys})
+	return keys, nil
+}
+
+// accountKeys returns the private keys of the accounts of the client: the privateKeys, or the keys of
+// the accountsKeystore directory, all of them unless accounts is set, or else the keys derived from
+// the wallet, which is nil when the keys are supplied.
+func (o *options) accountKeys(wallet *hdwallet.Wallet) ([]*ecdsa.PrivateKey, error) {
+	if len(o.PrivateKeys) > 0 {
+		keys := make([]*ecdsa.PrivateKey, len(o.PrivateKeys))
+		for i, hexKey := range o.PrivateKeys {
+			key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+			if err != nil {
+				return nil, fmt.Errorf("privateKeys[%d] is not a valid hex encoded private key", i)
+			}
+			keys[i] = key
+		}
+		return o.selectKeys(keys, "privateKeys")
+	}
+
+	if o.AccountsKeystore == "" {
+		keys := make([]*ecdsa.PrivateKey, o.Accounts)
+		for i := range keys {
+			keys[i] = wallet.Child(uint32(i)).MustGetPrivateKey()
+		}
+		return keys, nil
+	}
+
+	keys, err := keystoreKeys(o.AccountsKeystore, o.KeystorePassword)
+	if err != nil {
+		return nil, err
+	}
+	return o.selectKeys(keys, "the keystore")
+}
+
+// selectKeys returns the first accounts keys, or all of them when accounts is not set.
+func (o *options) selectKeys(keys []*ecdsa.PrivateKey, source string) ([]*ecdsa.PrivateKey, error) {
+	if o.Accounts == 0 {
+		o.Accounts = len(keys)
+	}
+	if o.Accounts > len(keys) {
+		return nil, fmt.Errorf("accounts is %d, but %s holds %d keys", o.Accounts, source, len(keys))
+	}
+	return keys[:o.Accounts], nil
+}
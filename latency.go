@@ -0,0 +1,140 @@
+package xk6_vechain
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// histogramSubBits gives every power of two 2^histogramSubBits buckets, for a relative error below 1%.
+const histogramSubBits = 7
+
+// histogram is an HDR-style log-linear histogram of durations in microseconds. Unlike the k6 trends,
+// it keeps every sample in a fixed-size bucket array, so high percentiles stay accurate on long runs.
+type histogram struct {
+	counts [(64 - histogramSubBits + 1) << histogramSubBits]uint64
+	total  uint64
+	sum    uint64
+	min    uint64
+	max    uint64
+}
+
+// bucketOf returns the index of the bucket holding the value.
+func bucketOf(v uint64) int {
+	if v < 1<<(histogramSubBits+1) {
+		return int(v)
+	}
+	shift := bits.Len64(v) - histogramSubBits - 1
+	mantissa := v >> shift
+	return (shift+1)<<histogramSubBits + int(mantissa) - 1<<histogramSubBits
+}
+
+// valueOf returns the highest value of the bucket.
+func valueOf(bucket int) uint64 {
+	if bucket < 1<<(histogramSubBits+1) {
+		return uint64(bucket)
+	}
+	shift := bucket>>histogramSubBits - 1
+	mantissa := uint64(bucket&(1<<histogramSubBits-1) + 1<<histogramSubBits)
+	return (mantissa+1)<<shift - 1
+}
+
+func (h *histogram) record(v uint64) {
+	if h.total == 0 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	h.counts[bucketOf(v)]++
+	h.total++
+	h.sum += v
+}
+
+// percentile returns the value at or below which p percent of the samples fall.
+func (h *histogram) percentile(p float64) uint64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(h.total)))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for bucket, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return min(valueOf(bucket), h.max)
+		}
+	}
+	return h.max
+}
+
+// latencyHistograms holds a histogram per endpoint of a node.
+type latencyHistograms struct {
+	mu     sync.Mutex
+	byCall map[string]*histogram
+}
+
+// latencies holds the latencyHistograms of each node URL.
+var latencies sync.Map
+
+// latenciesFor returns the latency histograms shared by every client of the node.
+func latenciesFor(url string) *latencyHistograms {
+	l, _ := latencies.LoadOrStore(url, &latencyHistograms{byCall: make(map[string]*histogram)})
+	return l.(*latencyHistograms)
+}
+
+// record adds the duration of a call to the histogram of its endpoint.
+func (l *latencyHistograms) record(call string, t time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h, ok := l.byCall[call]
+	if !ok {
+		h = &histogram{}
+		l.byCall[call] = h
+	}
+	h.record(uint64(t.Microseconds()))
+}
+
+// EndpointLatency summarizes the latency of a single endpoint, in milliseconds.
+type EndpointLatency struct {
+	Count uint64  `js:"count"`
+	Min   float64 `js:"min"`
+	Mean  float64 `js:"mean"`
+	P50   float64 `js:"p50"`
+	P90   float64 `js:"p90"`
+	P95   float64 `js:"p95"`
+	P99   float64 `js:"p99"`
+	P999  float64 `js:"p999"`
+	Max   float64 `js:"max"`
+}
+
+// LatencyReport returns the latency percentiles of every endpoint called on the node, keyed by
+// method and route, e.g. "GET /blocks/{id}". It is meant to be used from handleSummary.
+func (c *Client) LatencyReport() map[string]EndpointLatency {
+	l := latenciesFor(c.opts.URL)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ms := func(us uint64) float64 {
+		return float64(us) / 1000
+	}
+
+	report := make(map[string]EndpointLatency, len(l.byCall))
+	for call, h := range l.byCall {
+		report[call] = EndpointLatency{
+			Count: h.total,
+			Min:   ms(h.min),
+			Mean:  ms(h.sum) / float64(h.total),
+			P50:   ms(h.percentile(50)),
+			P90:   ms(h.percentile(90)),
+			P95:   ms(h.percentile(95)),
+			P99:   ms(h.percentile(99)),
+			P999:  ms(h.percentile(99.9)),
+			Max:   ms(h.max),
+		}
+	}
+	return report
+}
@@ -0,0 +1,80 @@
+package xk6_vechain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  uint64
+		bucket int
+		upper  uint64
+	}{
+		{name: "zero", value: 0, bucket: 0, upper: 0},
+		{name: "exact below the first power", value: 255, bucket: 255, upper: 255},
+		{name: "first shared bucket", value: 256, bucket: 256, upper: 257},
+		{name: "upper bound of the first shared bucket", value: 257, bucket: 256, upper: 257},
+		{name: "next bucket", value: 258, bucket: 257, upper: 259},
+		{name: "a second in microseconds", value: 1_000_000, bucket: 1780, upper: 1_003_519},
+		{name: "max", value: math.MaxUint64, bucket: len(histogram{}.counts) - 1, upper: math.MaxUint64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := bucketOf(tt.value)
+			if bucket != tt.bucket {
+				t.Fatalf("expected bucket %d, got %d", tt.bucket, bucket)
+			}
+			if upper := valueOf(bucket); upper != tt.upper {
+				t.Fatalf("expected the bucket to end at %d, got %d", tt.upper, upper)
+			}
+		})
+	}
+}
+
+func TestHistogramBucketsRelativeError(t *testing.T) {
+	for v := uint64(1); v < 1<<40; v = v*3 + 1 {
+		upper := valueOf(bucketOf(v))
+		if upper < v {
+			t.Fatalf("bucket of %d ends at %d, below the value", v, upper)
+		}
+		if float64(upper-v)/float64(v) >= 0.01 {
+			t.Fatalf("bucket of %d ends at %d, more than 1%% above the value", v, upper)
+		}
+		if bucketOf(upper) != bucketOf(v) {
+			t.Fatalf("the upper bound %d of the bucket of %d is in another bucket", upper, v)
+		}
+	}
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []uint64
+		percentile float64
+		expected   uint64
+	}{
+		{name: "empty", values: nil, percentile: 50, expected: 0},
+		{name: "single", values: []uint64{42}, percentile: 99, expected: 42},
+		{name: "zeroth", values: []uint64{3, 1, 2}, percentile: 0, expected: 1},
+		{name: "median", values: []uint64{1, 2, 3, 4}, percentile: 50, expected: 2},
+		{name: "rank rounded up", values: []uint64{1, 2, 3}, percentile: 50, expected: 2},
+		{name: "hundredth", values: []uint64{1, 2, 3}, percentile: 100, expected: 3},
+		{name: "capped at the max", values: []uint64{256, 256}, percentile: 50, expected: 256},
+		{name: "approximated", values: []uint64{258, 1000}, percentile: 50, expected: 259},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h histogram
+			for _, v := range tt.values {
+				h.record(v)
+			}
+			if p := h.percentile(tt.percentile); p != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, p)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package xk6_vechain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.k6.io/k6/lib/fsext"
+)
+
+const (
+	loadedAddresses = "address"
+	loadedKeys      = "privateKey"
+)
+
+// loadedFile identifies a file loaded by loadAddresses or loadKeys.
+type loadedFile struct {
+	path string
+	kind string
+}
+
+// loadedFiles holds the values of the loaded files, so that every file is parsed once across all VUs.
+var loadedFiles sync.Map // loadedFile -> []string
+
+// LoadAddresses reads the addresses of a CSV or JSON file, e.g. the recipients of a test, and returns
+// them checksummed. It can only be called in the init context, and the path is relative to the script,
+// as with open(), so that the file is bundled by k6 archive. The file is parsed once and shared by all
+// VUs. A JSON file holds an array of addresses, or of objects with an address field. A CSV file holds
+// an address per row, in its first column or in the column named address when it has a header.
+func (mi *ModuleInstance) LoadAddresses(path string) ([]string, error) {
+	return mi.loadFile(path, loadedAddresses, func(value string) (string, error) {
+		if !common.IsHexAddress(value) {
+			return "", fmt.Errorf("invalid address %q", value)
+		}
+		return common.HexToAddress(value).Hex(), nil
+	})
+}
+
+// LoadKeys reads the hex encoded private keys of a CSV or JSON file, laid out as for loadAddresses with a
+// privateKey field or column, and returns them 0x prefixed. Like loadAddresses, it can only be called in
+// the init context. The file is parsed once and shared by all VUs.
+func (mi *ModuleInstance) LoadKeys(path string) ([]string, error) {
+	return mi.loadFile(path, loadedKeys, func(value string) (string, error) {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return "", errors.New("invalid private key")
+		}
+		return hexutil.Encode(crypto.FromECDSA(key)), nil
+	})
+}
+
+// loadFile returns the values of the field of the file, parsing and validating them on first use.
+// The file is read through the file system of the init environment, relative to the script.
+// Every VU gets its own copy of the values.
+func (mi *ModuleInstance) loadFile(path, field string, parse func(string) (string, error)) ([]string, error) {
+	initEnv := mi.vu.InitEnv()
+	if initEnv == nil {
+		return nil, errors.New("files can only be loaded in the init context")
+	}
+	if path == "" {
+		return nil, errors.New("the path is empty")
+	}
+	fs, ok := initEnv.FileSystems["file"]
+	if !ok {
+		return nil, errors.New("the file system is not available")
+	}
+	path = initEnv.GetAbsFilePath(path)
+
+	key := loadedFile{path: path, kind: field}
+	if values, ok := loadedFiles.Load(key); ok {
+		return append([]string(nil), values.([]string)...), nil
+	}
+
+	data, err := fsext.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	var raw []string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		raw, err = readJSONField(bytes.NewReader(data), field)
+	} else {
+		raw, err = readCSVField(bytes.NewReader(data), field, parse)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	values := make([]string, len(raw))
+	for i, value := range raw {
+		if values[i], err = parse(strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("entry %d of %s: %w", i+1, path, err)
+		}
+	}
+
+	loaded, _ := loadedFiles.LoadOrStore(key, values)
+	return append([]string(nil), loaded.([]string)...), nil
+}
+
+// readJSONField reads an array of strings, or of objects holding the field.
+func readJSONField(r io.Reader, field string) ([]string, error) {
+	var entries []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		if err := json.Unmarshal(entry, &values[i]); err == nil {
+			continue
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal(entry, &object); err != nil {
+			return nil, fmt.Errorf("entry %d is neither a string nor an object", i+1)
+		}
+		value, ok := object[field].(string)
+		if !ok {
+			return nil, fmt.Errorf("entry %d has no %s", i+1, field)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// readCSVField reads the first column of the rows, or the column named after the field when the first
+// row is a header, which is told apart by its first cell not being a valid value.
+func readCSVField(r io.Reader, field string, parse func(string) (string, error)) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	column := 0
+	if len(records) > 0 && len(records[0]) > 0 {
+		if _, err := parse(strings.TrimSpace(records[0][0])); err != nil {
+			column = -1
+			for i, name := range records[0] {
+				if strings.EqualFold(strings.TrimSpace(name), field) {
+					column = i
+				}
+			}
+			if column < 0 {
+				return nil, fmt.Errorf("the header has no %s column", field)
+			}
+			records = records[1:]
+		}
+	}
+
+	values := make([]string, 0, len(records))
+	for i, record := range records {
+		if column >= len(record) {
+			return nil, fmt.Errorf("row %d has no %s", i+1, field)
+		}
+		values = append(values, record[column])
+	}
+	return values, nil
+}
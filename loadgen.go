@@ -0,0 +1,148 @@
+package xk6_vechain
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"runtime"
+	runtimemetrics "runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// loadgenSampleInterval is how often the resource usage of the load generator is sampled.
+const loadgenSampleInterval = 5 * time.Second
+
+// openConns counts the connections to the nodes that are currently open.
+var openConns atomic.Int64
+
+// nodeTransport is the transport of every client, shared so that connections are pooled across VUs.
+// It counts the connections it opens in openConns.
+var nodeTransport = newCountingTransport()
+
+func newCountingTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		openConns.Add(1)
+		return &countedConn{Conn: conn}, nil
+	}
+	return transport
+}
+
+// countedConn decrements openConns once closed.
+type countedConn struct {
+	net.Conn
+	closeOnce sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.closeOnce.Do(func() { openConns.Add(-1) })
+	return c.Conn.Close()
+}
+
+// loadgenSampler samples the resource usage of the process on behalf of every client that sets
+// loadgenMetrics, so that the process is sampled once however many VUs there are. It runs while at
+// least one client holds it.
+var loadgenSampler struct {
+	mu      sync.Mutex
+	clients []*Client
+	cancel  context.CancelFunc
+	busy    float64 // CPU seconds the process was busy for at the previous sample
+	all     float64 // CPU seconds available to the process at the previous sample
+}
+
+// runtimeSamples are the runtime metrics the CPU and memory usage are read from.
+var runtimeSamples = []runtimemetrics.Sample{
+	{Name: "/cpu/classes/total:cpu-seconds"},
+	{Name: "/cpu/classes/idle:cpu-seconds"},
+	{Name: "/memory/classes/total:bytes"},
+}
+
+// acquireLoadgenSampler adds the client to the load generator sampler, starting it for the first client.
+// The client is removed once it is closed, at the latest when the test ends.
+func (c *Client) acquireLoadgenSampler() {
+	loadgenSampler.mu.Lock()
+	defer loadgenSampler.mu.Unlock()
+
+	loadgenSampler.clients = append(loadgenSampler.clients, c)
+	if len(loadgenSampler.clients) == 1 {
+		ctx, cancel := context.WithCancel(context.Background())
+		loadgenSampler.cancel = cancel
+		go sampleLoadgen(ctx)
+	}
+
+	context.AfterFunc(c.ctx, c.releaseLoadgenSampler)
+}
+
+// releaseLoadgenSampler removes the client from the load generator sampler, stopping it with the last client.
+func (c *Client) releaseLoadgenSampler() {
+	loadgenSampler.mu.Lock()
+	defer loadgenSampler.mu.Unlock()
+
+	for i, client := range loadgenSampler.clients {
+		if client == c {
+			loadgenSampler.clients = append(loadgenSampler.clients[:i], loadgenSampler.clients[i+1:]...)
+			break
+		}
+	}
+	if len(loadgenSampler.clients) == 0 {
+		loadgenSampler.cancel()
+	}
+}
+
+// loadgenReporter returns the client the samples are pushed through: the first one whose VU is
+// running, or nil when there is none.
+func loadgenReporter() *Client {
+	loadgenSampler.mu.Lock()
+	defer loadgenSampler.mu.Unlock()
+	for _, c := range loadgenSampler.clients {
+		if c.vu.State() != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// sampleLoadgen periodically emits the resource usage of the load generator itself, in
+// vechain_loadgen_cpu, the share of the available CPU used in percent as estimated by the Go runtime,
+// vechain_loadgen_memory, vechain_loadgen_goroutines and vechain_loadgen_open_conns, so that a
+// saturated k6 can be told apart from a saturated node. It returns once the sampler is stopped.
+func sampleLoadgen(ctx context.Context) {
+	ticker := time.NewTicker(loadgenSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		c := loadgenReporter()
+		if c == nil {
+			continue
+		}
+
+		samples := make([]runtimemetrics.Sample, len(runtimeSamples))
+		copy(samples, runtimeSamples)
+		runtimemetrics.Read(samples)
+		all, idle, memory := samples[0].Value.Float64(), samples[1].Value.Float64(), samples[2].Value.Uint64()
+
+		loadgenSampler.mu.Lock()
+		busy := all - idle
+		if elapsed := all - loadgenSampler.all; loadgenSampler.all > 0 && elapsed > 0 {
+			c.pushSample(c.metrics.LoadgenCPU, (busy-loadgenSampler.busy)/elapsed*100, nil)
+		}
+		loadgenSampler.busy, loadgenSampler.all = busy, all
+		loadgenSampler.mu.Unlock()
+
+		c.pushSample(c.metrics.LoadgenMemory, float64(memory), nil)
+		c.pushSample(c.metrics.LoadgenGoroutines, float64(runtime.NumGoroutine()), nil)
+		c.pushSample(c.metrics.LoadgenOpenConns, float64(openConns.Load()), nil)
+	}
+}
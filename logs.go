@@ -0,0 +1,136 @@
+package xk6_vechain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+)
+
+const (
+	// logsPageSize is the number of logs requested per page, matching the default limit of thor.
+	logsPageSize = 1000
+	// maxLogs is the safety cap on the number of logs a paginated query retrieves.
+	maxLogs = 1_000_000
+)
+
+// QueryEventsAll returns every event log matching the filter, paginating through /logs/event
+// until a page comes back short. The filter takes the same shape as the thor API body, the
+// options.limit, if set, is used as the page size. The logs are returned as served by the node, with
+// the events of registered contracts decoded into a decoded field.
+func (c *Client) QueryEventsAll(filter map[string]interface{}) ([]map[string]interface{}, error) {
+	var eventFilter client.EventFilter
+	if err := decodeOptions(filter, &eventFilter); err != nil {
+		return nil, err
+	}
+
+	logs, err := filterEventsAll(c.thor.Client, &eventFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := toJSON(logs)
+	if err != nil {
+		return nil, err
+	}
+
+	abis := abisFor(c.opts.URL)
+	for i, log := range logs {
+		if log.Address == nil {
+			continue
+		}
+		if args := abis.decode(*log.Address, log.Topics, log.Data); args != nil {
+			decoded[i]["decoded"] = args
+		}
+	}
+	return decoded, nil
+}
+
+// QueryTransfersAll returns every transfer log matching the filter, paginating through /logs/transfer
+// the same way as QueryEventsAll.
+func (c *Client) QueryTransfersAll(filter map[string]interface{}) ([]map[string]interface{}, error) {
+	var transferFilter client.TransferFilter
+	if err := decodeOptions(filter, &transferFilter); err != nil {
+		return nil, err
+	}
+
+	logs, err := filterTransfersAll(c.thor.Client, &transferFilter)
+	if err != nil {
+		return nil, err
+	}
+	return toJSON(logs)
+}
+
+// filterEventsAll pages through the event logs of the node matching the filter.
+func filterEventsAll(thorClient *client.Client, filter *client.EventFilter) ([]client.EventLog, error) {
+	offset, limit := pagination(filter.Options)
+	all := make([]client.EventLog, 0)
+
+	for {
+		filter.Options = &client.FilterOptions{Offset: &offset, Limit: &limit}
+		page, err := thorClient.FilterEvents(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query events at offset %d: %w", offset, err)
+		}
+		all = append(all, page...)
+
+		if uint64(len(page)) < limit {
+			return all, nil
+		}
+		if len(all) >= maxLogs {
+			return nil, fmt.Errorf("query matched more than %d events, narrow the range", maxLogs)
+		}
+		offset += limit
+	}
+}
+
+// filterTransfersAll pages through the transfer logs of the node matching the filter.
+func filterTransfersAll(thorClient *client.Client, filter *client.TransferFilter) ([]client.TransferLog, error) {
+	offset, limit := pagination(filter.Options)
+	all := make([]client.TransferLog, 0)
+
+	for {
+		filter.Options = &client.FilterOptions{Offset: &offset, Limit: &limit}
+		page, err := thorClient.FilterTransfers(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query transfers at offset %d: %w", offset, err)
+		}
+		all = append(all, page...)
+
+		if uint64(len(page)) < limit {
+			return all, nil
+		}
+		if len(all) >= maxLogs {
+			return nil, fmt.Errorf("query matched more than %d transfers, narrow the range", maxLogs)
+		}
+		offset += limit
+	}
+}
+
+// pagination returns the starting offset and page size for the filter options.
+func pagination(opts *client.FilterOptions) (uint64, uint64) {
+	var offset, limit uint64 = 0, logsPageSize
+	if opts != nil {
+		if opts.Offset != nil {
+			offset = *opts.Offset
+		}
+		if opts.Limit != nil && *opts.Limit > 0 {
+			limit = *opts.Limit
+		}
+	}
+	return offset, limit
+}
+
+// toJSON converts v to its JSON representation, so that scripts see the field names of the thor API.
+func toJSON[T any](v T) ([]map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
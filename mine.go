@@ -0,0 +1,149 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// receiptPollInterval is how often the receipt of a transaction is polled while waiting for it.
+	receiptPollInterval = 250 * time.Millisecond
+	// defaultMineTimeout is how long sendAndWait waits for the receipt unless a timeout is given.
+	defaultMineTimeout = time.Minute
+)
+
+// errNotMined is returned by sendAndWait when the receipt never appears.
+var errNotMined = errors.New("transaction not mined")
+
+// mineOptions configures sendAndWait.
+type mineOptions struct {
+	// Timeout is how long to wait for the receipt, e.g. "30s".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// MinedTx is the outcome of sendAndWait. TimeToMine is the time from submission to the receipt, in ms.
+type MinedTx struct {
+	TxID       string                 `js:"txID"`
+	TimeToMine float64                `js:"timeToMine"`
+	Receipt    map[string]interface{} `js:"receipt"`
+}
+
+// SendAndWait sends the hex encoded transaction, e.g. from newToolchainTransaction, and waits for its
+// receipt. The time from submission to inclusion is recorded in vechain_time_to_mine, tagged with whether
+// the transaction reverted. A reverted transaction fails the call with the code reverted. A transaction
+// whose receipt never appears counts in vechain_tx_not_mined, tagged expired when it outlived its
+// expiration and timeout when the timeout elapsed first, and fails the call.
+func (c *Client) SendAndWait(raw string, options map[string]interface{}) (*MinedTx, error) {
+	timeout, err := mineTimeout(options)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendAndWait(raw, timeout, nil)
+}
+
+// SendAndWaitAsync is the promise-returning variant of SendAndWait.
+func (c *Client) SendAndWaitAsync(raw string, options map[string]interface{}) *sobek.Promise {
+	timeout, err := mineTimeout(options)
+
+	return c.async(func() (any, error) {
+		if err != nil {
+			return nil, err
+		}
+		return c.sendAndWait(raw, timeout, nil)
+	})
+}
+
+// mineTimeout returns the timeout of the sendAndWait options.
+func mineTimeout(options map[string]interface{}) (time.Duration, error) {
+	var opts mineOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return 0, err
+	}
+	if opts.Timeout == "" {
+		return defaultMineTimeout, nil
+	}
+	timeout, err := time.ParseDuration(opts.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	return timeout, nil
+}
+
+// sendAndWait sends the transaction and waits for its receipt, adding the tags to its vechain_time_to_mine sample.
+func (c *Client) sendAndWait(raw string, timeout time.Duration, tags map[string]string) (*MinedTx, error) {
+	if !strings.HasPrefix(raw, "0x") {
+		raw = "0x" + raw
+	}
+	tx, err := decodeRaw(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	origin, err := tx.Origin()
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+	signer := c.signerIndex(origin)
+
+	submitted := time.Now()
+	id, err := c.sendInFlight(raw, "send and wait", signer)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := submitted.Add(timeout)
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+	for {
+		receipt, err := c.thor.Client.TransactionReceipt(id)
+		if err == nil {
+			elapsed := time.Since(submitted)
+			mineTags := map[string]string{"reverted": strconv.FormatBool(receipt.Reverted)}
+			for key, value := range tags {
+				mineTags[key] = value
+			}
+			c.pushSample(c.metrics.TimeToMine, metrics.D(elapsed), c.signerTags(signer, mineTags))
+			c.checkTimeToMine(elapsed)
+			c.observeReceipt(receipt)
+			if receipt.Reverted {
+				return nil, revertedError(id)
+			}
+
+			decoded, err := c.decodeReceipt(receipt)
+			if err != nil {
+				return nil, err
+			}
+			return &MinedTx{
+				TxID:       id.Hex(),
+				TimeToMine: metrics.D(elapsed),
+				Receipt:    decoded,
+			}, nil
+		}
+		if !errors.Is(err, client.ErrNotFound) {
+			return nil, c.fail(fmt.Errorf("failed to fetch receipt of %s: %w", id.Hex(), err))
+		}
+
+		reason := ""
+		if best := statsFor(c.opts.URL).lastBlock.Load(); best > 0 && tx.IsExpired(uint32(best)) {
+			reason = errorExpired
+		} else if time.Now().After(deadline) {
+			reason = errorTimeout
+		}
+		if reason != "" {
+			c.pushSample(c.metrics.TxNotMined, 1, c.signerTags(signer, map[string]string{"reason": reason}))
+			return nil, c.failAs(reason, fmt.Errorf("%w: %s %s", errNotMined, id.Hex(), reason))
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package xk6_vechain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/thor-go-sdk/thorgo"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func TestSendAndWaitReverted(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := new(transaction.Builder).ChainTag(0xf6).Gas(21000).Expiration(32).Nonce(1).Build()
+	signature, err := crypto.Sign(unsigned.SigningHash().Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := unsigned.WithSignature(signature)
+	raw, err := tx.Encoded()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		reverted bool
+		code     string
+	}{
+		{name: "mined", reverted: false},
+		{name: "reverted", reverted: true, code: errorReverted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/transactions":
+					_ = json.NewEncoder(w).Encode(map[string]string{"id": tx.ID().Hex()})
+				case "/transactions/" + tx.ID().Hex() + "/receipt":
+					_ = json.NewEncoder(w).Encode(client.TransactionReceipt{
+						Reverted: tt.reverted,
+						Meta:     client.ReceiptMeta{TxID: tx.ID()},
+						Outputs:  []client.Output{},
+					})
+				default:
+					_, _ = w.Write([]byte("{}"))
+				}
+			}))
+			defer server.Close()
+
+			opts, err := newOptionsFrom(map[string]interface{}{"url": server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			thorClient, err := client.New(server.URL, server.Client())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c := &Client{
+				thor:    thorgo.FromClient(thorClient),
+				vu:      &modulestest.VU{CtxField: ctx},
+				ctx:     ctx,
+				opts:    opts,
+				tracker: newTxTracker(statsFor(opts.URL), opts.TrackFinality),
+			}
+
+			mined, err := c.sendAndWait(raw, defaultMineTimeout, nil)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if mined.TxID != tx.ID().Hex() {
+					t.Fatalf("expected the transaction %s, got %s", tx.ID().Hex(), mined.TxID)
+				}
+				return
+			}
+			var classified *Error
+			if !errors.As(err, &classified) || classified.Code != tt.code {
+				t.Fatalf("expected an error with the code %q, got %v", tt.code, err)
+			}
+		})
+	}
+}
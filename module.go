@@ -2,24 +2,38 @@ package xk6_vechain
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/darrenvechain/thor-go-sdk/client"
 	"github.com/darrenvechain/thor-go-sdk/crypto/hdwallet"
 	"github.com/darrenvechain/thor-go-sdk/thorgo"
 	"github.com/darrenvechain/thor-go-sdk/txmanager"
 	"github.com/darrenvechain/xk6-vechain/accounts"
+	"github.com/darrenvechain/xk6-vechain/random"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/grafana/sobek"
+	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/js/common"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/metrics"
 )
 
 const (
+	// mnemonic is the mnemonic of thor solo, whose first soloAccounts accounts are funded in the genesis block.
 	mnemonic      = "denial kitchen pet squirrel other broom bar gas better priority spoil cross"
 	accountAmount = 10
+	soloAccounts  = 10
 )
 
 type vechainMetrics struct {
@@ -28,12 +42,82 @@ type vechainMetrics struct {
 	Block           *metrics.Metric
 	GasUsed         *metrics.Metric
 	TPS             *metrics.Metric
+	CPS             *metrics.Metric
 	BlockTime       *metrics.Metric
+	MonitorErrors   *metrics.Metric
+	StateGrowth     *metrics.Metric
+	TxPoolSize      *metrics.Metric
+	OriginTxs       *metrics.Metric
+	OwnTPS          *metrics.Metric
+
+	ForeignGasPriceCoef *metrics.Metric
+	BlockUtilization    *metrics.Metric
+	FinalityLag         *metrics.Metric
+	Reorgs              *metrics.Metric
+	MissedSlots         *metrics.Metric
+
+	WSDeliveryLatency *metrics.Metric
+	WSReconnects      *metrics.Metric
+	WSGapBlocks       *metrics.Metric
+
+	FundDuration      *metrics.Metric
+	FundBatchDuration *metrics.Metric
+	FundRate          *metrics.Metric
+	FaucetRequests    *metrics.Metric
+	FaucetProgress    *metrics.Metric
+
+	DeployDuration *metrics.Metric
+	DeployGas      *metrics.Metric
+
+	MempoolAcceptTime *metrics.Metric
+	TxConfirmed       *metrics.Metric
+	InclusionShare    *metrics.Metric
+	SignerConflicts   *metrics.Metric
+	FinalityWait      *metrics.Metric
+	TimeToFinality    *metrics.Metric
+	TxNotMined        *metrics.Metric
+	TxReverted        *metrics.Metric
+	Errors            *metrics.Metric
+	SubmitDuration    *metrics.Metric
+	ChainDuration     *metrics.Metric
+	SLOBreach         *metrics.Metric
+	DepositLatency    *metrics.Metric
+	BridgeLatency     *metrics.Metric
+	NodeDowntime      *metrics.Metric
+
+	VTHOBurned   *metrics.Metric
+	VTHORewarded *metrics.Metric
+
+	Operations        *metrics.Metric
+	OperationDuration *metrics.Metric
+
+	LastBlock   *metrics.Metric
+	ObservedTxs *metrics.Metric
+	LeftoverTxs *metrics.Metric
+
+	TrackedItems *metrics.Metric
+
+	LoadgenCPU        *metrics.Metric
+	LoadgenMemory     *metrics.Metric
+	LoadgenGoroutines *metrics.Metric
+	LoadgenOpenConns  *metrics.Metric
+
+	DrainCompleted *metrics.Metric
+	DrainAbandoned *metrics.Metric
+
+	InjectorRate *metrics.Metric
+	TargetTPS    *metrics.Metric
+
+	TransfersVerified   *metrics.Metric
+	TransfersMissing    *metrics.Metric
+	TransfersUnexpected *metrics.Metric
+	ReconciledTxs       *metrics.Metric
 }
 
 func init() {
 	modules.Register("k6/x/vechain", &EthRoot{})
 	modules.Register("k6/x/vechain/accounts", &accounts.Account{})
+	modules.Register("k6/x/vechain/random", &random.Random{})
 }
 
 // EthRoot is the root module
@@ -42,20 +126,24 @@ type EthRoot struct{}
 // NewModuleInstance implements the modules.Module interface returning a new instance for each VU.
 func (*EthRoot) NewModuleInstance(vu modules.VU) modules.Instance {
 	return &ModuleInstance{
-		vu: vu,
-		m:  registerMetrics(vu),
+		vu:       vu,
+		m:        registerMetrics(vu.InitEnv().Registry, defaultMetricPrefix),
+		registry: vu.InitEnv().Registry,
 	}
 }
 
 type ModuleInstance struct {
-	vu modules.VU
-	m  vechainMetrics
+	vu       modules.VU
+	m        vechainMetrics
+	registry *metrics.Registry
 }
 
 // Exports implements the modules.Instance interface and returns the exported types for the JS module.
 func (mi *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{Named: map[string]interface{}{
-		"Client": mi.NewClient,
+		"Client":        mi.NewClient,
+		"loadAddresses": mi.LoadAddresses,
+		"loadKeys":      mi.LoadKeys,
 	}}
 }
 
@@ -73,79 +161,276 @@ func (mi *ModuleInstance) NewClient(call sobek.ConstructorCall) *sobek.Object {
 		common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
 	}
 
-	if opts.URL == "" {
-		opts.URL = "http://localhost:8669"
-	}
-
-	if opts.Mnemonic == "" {
-		opts.Mnemonic = mnemonic
-	}
-
-	if opts.Accounts == 0 {
-		opts.Accounts = accountAmount
+	var wa *hdwallet.Wallet
+	if opts.Mnemonic != "" {
+		if wa, err = hdwallet.FromMnemonic(opts.Mnemonic); err != nil {
+			common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
+		}
 	}
 
-	wa, err := hdwallet.FromMnemonic(opts.Mnemonic)
+	keys, err := opts.accountKeys(wa)
 	if err != nil {
 		common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
 	}
 
-	thor, err := thorgo.FromURL(opts.URL)
+	transport := newInstrumentedTransport(opts.URL)
+	if opts.RequestIDs {
+		transport.requests = new(requestLog)
+	}
+	httpClient := &http.Client{Transport: transport}
+	thorClient, err := client.New(opts.URL, httpClient)
 	if err != nil {
 		common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
 	}
+	thor := thorgo.FromClient(thorClient)
 
 	chainTag := thor.Client.ChainTag()
 
-	managers := make([]*txmanager.PKManager, opts.Accounts)
-	for i := 0; i < opts.Accounts; i++ {
-		key := wa.Child(uint32(i)).MustGetPrivateKey()
-		manager := txmanager.FromPK(key, thor)
+	var nodes []submissionNode
+	transports := []*instrumentedTransport{transport}
+	if len(opts.URLs) > 0 {
+		nodes, err = newSubmissionNodes(opts.URLs, chainTag, func(url string) (*client.Client, error) {
+			if url == opts.URL {
+				return client.New(url, httpClient)
+			}
+			// every node has its own transport, so that its requests are reported under its URL
+			nodeTransport := newInstrumentedTransport(url)
+			nodeTransport.requests = transport.requests
+			transports = append(transports, nodeTransport)
+			return client.New(url, &http.Client{Transport: nodeTransport})
+		})
 		if err != nil {
-			common.Throw(rt, fmt.Errorf("failed to create tx manager: %w", err))
+			common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
 		}
+	}
 
+	managers := make([]*txmanager.PKManager, len(keys))
+	managed := make(map[ethcommon.Address]int, len(keys))
+	for i, key := range keys {
+		manager := txmanager.FromPK(key, thor)
 		managers[i] = manager
+		managed[manager.Address()] = i
+	}
+
+	m := mi.m
+	if opts.MetricPrefix != "" && opts.MetricPrefix != defaultMetricPrefix {
+		m = registerMetrics(mi.registry, opts.MetricPrefix)
+	}
+
+	// clients are constructed in the init context, or by a running VU
+	var logger logrus.FieldLogger
+	if env := mi.vu.InitEnv(); env != nil {
+		logger = env.Logger
+	} else {
+		logger = mi.vu.State().Logger
 	}
 
-	client := &Client{
+	c := &Client{
 		vu:       mi.vu,
-		metrics:  mi.m,
+		logger:   logger,
+		metrics:  m,
+		registry: mi.registry,
 		thor:     thor,
+		http:     httpClient,
 		wallet:   wa,
 		chainTag: chainTag,
 		opts:     opts,
 		accounts: opts.Accounts,
 		managers: managers,
+		keys:     keys,
+		managed:  managed,
+		nodes:    nodes,
+		requests: transport.requests,
+		tracker:  newTxTracker(statsFor(opts.URL), opts.TrackFinality),
+		signers:  new(atomic.Uint64),
+	}
+	if opts.SignerScope == signerScopeGlobal {
+		c.signers = signerCounterFor(opts.URL)
+	}
+
+	for _, t := range transports {
+		t.report = c.reportMetricsFromStats
 	}
 
-	go client.pollForBlocks()
+	if err := c.start(); err != nil {
+		common.Throw(rt, fmt.Errorf("invalid options; reason: %w", err))
+	}
 
-	return rt.ToValue(client).ToObject(rt)
+	return rt.ToValue(c).ToObject(rt)
 }
 
-func registerMetrics(vu modules.VU) vechainMetrics {
-	registry := vu.InitEnv().Registry
+// start runs the background goroutines of the client until it is closed or the test ends. They are not
+// tied to the VU context, which is the init context while the client is constructed and is done once
+// the VU is initialized.
+func (c *Client) start() error {
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.closeOnTestEnd()
+
+	if !c.opts.DisableBlockMetrics {
+		if err := c.acquirePoller(); err != nil {
+			c.cancel()
+			return err
+		}
+	}
+
+	if c.opts.StateMetricsURL != "" {
+		go c.sampleStateGrowth()
+	}
+
+	if c.opts.TxPoolMetrics {
+		go c.sampleTxPoolSize()
+	}
+
+	if c.opts.LoadgenMetrics {
+		c.acquireLoadgenSampler()
+	}
+	return nil
+}
+
+// defaultMetricPrefix is the prefix of the names of the metrics, unless metricPrefix is set.
+const defaultMetricPrefix = "vechain_"
+
+// metricPrefixPattern matches the prefixes that keep the metric names valid for k6.
+var metricPrefixPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]{0,63}$`)
+
+// prefixedRegistry registers the metrics under a prefix in place of vechain_.
+type prefixedRegistry struct {
+	*metrics.Registry
+	prefix string
+}
+
+// MustNewMetric registers the metric, replacing the vechain_ prefix of the name with the prefix.
+func (r prefixedRegistry) MustNewMetric(name string, typ metrics.MetricType, t ...metrics.ValueType) *metrics.Metric {
+	return r.Registry.MustNewMetric(r.prefix+strings.TrimPrefix(name, defaultMetricPrefix), typ, t...)
+}
+
+// registerMetrics registers the metrics of the extension, with their names prefixed by prefix.
+func registerMetrics(r *metrics.Registry, prefix string) vechainMetrics {
+	registry := prefixedRegistry{Registry: r, prefix: prefix}
 	m := vechainMetrics{
 		RequestDuration: registry.MustNewMetric("vechain_req_duration", metrics.Trend, metrics.Time),
 		TimeToMine:      registry.MustNewMetric("vechain_time_to_mine", metrics.Trend, metrics.Time),
 		Block:           registry.MustNewMetric("vechain_block", metrics.Counter, metrics.Default),
 		GasUsed:         registry.MustNewMetric("vechain_gas_used", metrics.Trend, metrics.Default),
 		TPS:             registry.MustNewMetric("vechain_tps", metrics.Trend, metrics.Default),
+		CPS:             registry.MustNewMetric("vechain_cps", metrics.Trend, metrics.Default),
 		BlockTime:       registry.MustNewMetric("vechain_block_time", metrics.Trend, metrics.Time),
+		MonitorErrors:   registry.MustNewMetric("vechain_monitor_errors", metrics.Counter, metrics.Default),
+		StateGrowth:     registry.MustNewMetric("vechain_state_growth", metrics.Gauge, metrics.Default),
+		TxPoolSize:      registry.MustNewMetric("vechain_txpool_size", metrics.Gauge, metrics.Default),
+		OriginTxs:       registry.MustNewMetric("vechain_origin_txs", metrics.Trend, metrics.Default),
+		OwnTPS:          registry.MustNewMetric("vechain_own_tps", metrics.Trend, metrics.Default),
+
+		ForeignGasPriceCoef: registry.MustNewMetric("vechain_foreign_gas_price_coef", metrics.Trend, metrics.Default),
+		BlockUtilization:    registry.MustNewMetric("vechain_block_utilization", metrics.Trend, metrics.Default),
+		FinalityLag:         registry.MustNewMetric("vechain_finality_lag", metrics.Gauge, metrics.Default),
+		Reorgs:              registry.MustNewMetric("vechain_reorg", metrics.Counter, metrics.Default),
+		MissedSlots:         registry.MustNewMetric("vechain_missed_slots", metrics.Counter, metrics.Default),
+
+		WSDeliveryLatency: registry.MustNewMetric("vechain_ws_delivery_latency", metrics.Trend, metrics.Time),
+		WSReconnects:      registry.MustNewMetric("vechain_ws_reconnects", metrics.Counter, metrics.Default),
+		WSGapBlocks:       registry.MustNewMetric("vechain_ws_gap_blocks", metrics.Counter, metrics.Default),
+
+		FundDuration:      registry.MustNewMetric("vechain_fund_duration", metrics.Trend, metrics.Time),
+		FundBatchDuration: registry.MustNewMetric("vechain_fund_batch_duration", metrics.Trend, metrics.Time),
+		FundRate:          registry.MustNewMetric("vechain_fund_rate", metrics.Trend, metrics.Default),
+		FaucetRequests:    registry.MustNewMetric("vechain_faucet_requests", metrics.Counter, metrics.Default),
+		FaucetProgress:    registry.MustNewMetric("vechain_faucet_progress", metrics.Gauge, metrics.Default),
+
+		DeployDuration: registry.MustNewMetric("vechain_deploy_duration", metrics.Trend, metrics.Time),
+		DeployGas:      registry.MustNewMetric("vechain_deploy_gas", metrics.Trend, metrics.Default),
+
+		MempoolAcceptTime: registry.MustNewMetric("vechain_mempool_accept_time", metrics.Trend, metrics.Time),
+		TxConfirmed:       registry.MustNewMetric("vechain_tx_confirmed", metrics.Counter, metrics.Default),
+		InclusionShare:    registry.MustNewMetric("vechain_inclusion_share", metrics.Trend, metrics.Default),
+		SignerConflicts:   registry.MustNewMetric("vechain_signer_conflicts", metrics.Counter, metrics.Default),
+		FinalityWait:      registry.MustNewMetric("vechain_finality_wait", metrics.Trend, metrics.Time),
+		TimeToFinality:    registry.MustNewMetric("vechain_time_to_finality", metrics.Trend, metrics.Time),
+		TxNotMined:        registry.MustNewMetric("vechain_tx_not_mined", metrics.Counter, metrics.Default),
+		TxReverted:        registry.MustNewMetric("vechain_tx_reverted", metrics.Counter, metrics.Default),
+		Errors:            registry.MustNewMetric("vechain_errors", metrics.Counter, metrics.Default),
+		SubmitDuration:    registry.MustNewMetric("vechain_submit_duration", metrics.Trend, metrics.Time),
+		ChainDuration:     registry.MustNewMetric("vechain_chain_duration", metrics.Trend, metrics.Time),
+		SLOBreach:         registry.MustNewMetric("vechain_slo_breach", metrics.Rate, metrics.Default),
+		DepositLatency:    registry.MustNewMetric("vechain_deposit_detection_latency", metrics.Trend, metrics.Time),
+		BridgeLatency:     registry.MustNewMetric("vechain_bridge_event_latency", metrics.Trend, metrics.Time),
+		NodeDowntime:      registry.MustNewMetric("vechain_node_downtime", metrics.Trend, metrics.Time),
+
+		VTHOBurned:   registry.MustNewMetric("vechain_vtho_burned", metrics.Counter, metrics.Default),
+		VTHORewarded: registry.MustNewMetric("vechain_vtho_rewarded", metrics.Counter, metrics.Default),
+
+		Operations:        registry.MustNewMetric("vechain_operations", metrics.Counter, metrics.Default),
+		OperationDuration: registry.MustNewMetric("vechain_operation_duration", metrics.Trend, metrics.Time),
+
+		LastBlock:   registry.MustNewMetric("vechain_last_block", metrics.Gauge, metrics.Default),
+		ObservedTxs: registry.MustNewMetric("vechain_observed_txs", metrics.Gauge, metrics.Default),
+		LeftoverTxs: registry.MustNewMetric("vechain_leftover_txs", metrics.Gauge, metrics.Default),
+
+		TrackedItems: registry.MustNewMetric("vechain_internal_tracked_items", metrics.Gauge, metrics.Default),
+
+		LoadgenCPU:        registry.MustNewMetric("vechain_loadgen_cpu", metrics.Gauge, metrics.Default),
+		LoadgenMemory:     registry.MustNewMetric("vechain_loadgen_memory", metrics.Gauge, metrics.Data),
+		LoadgenGoroutines: registry.MustNewMetric("vechain_loadgen_goroutines", metrics.Gauge, metrics.Default),
+		LoadgenOpenConns:  registry.MustNewMetric("vechain_loadgen_open_conns", metrics.Gauge, metrics.Default),
+
+		DrainCompleted: registry.MustNewMetric("vechain_drain_completed", metrics.Gauge, metrics.Default),
+		DrainAbandoned: registry.MustNewMetric("vechain_drain_abandoned", metrics.Gauge, metrics.Default),
+
+		InjectorRate: registry.MustNewMetric("vechain_injector_rate", metrics.Gauge, metrics.Default),
+		TargetTPS:    registry.MustNewMetric("vechain_target_tps", metrics.Gauge, metrics.Default),
+
+		TransfersVerified:   registry.MustNewMetric("vechain_transfers_verified", metrics.Counter, metrics.Default),
+		TransfersMissing:    registry.MustNewMetric("vechain_transfers_missing", metrics.Counter, metrics.Default),
+		TransfersUnexpected: registry.MustNewMetric("vechain_transfers_unexpected", metrics.Counter, metrics.Default),
+		ReconciledTxs:       registry.MustNewMetric("vechain_reconciled_txs", metrics.Counter, metrics.Default),
 	}
 
 	return m
 }
 
-func (c *Client) reportMetricsFromStats(call string, t time.Duration) {
-	registry := metrics.NewRegistry()
-	metrics.PushIfNotDone(c.vu.Context(), c.vu.State().Samples, metrics.Sample{
+// reportMetricsFromStats records the duration of a call to the node, tagged with the response status,
+// and adds it to the latency histogram of the endpoint.
+func (c *Client) reportMetricsFromStats(url, call string, t time.Duration, status int) {
+	latenciesFor(url).record(call, t)
+	c.pushNodeSample(c.nodeLabel(url), c.metrics.RequestDuration, metrics.D(t), map[string]string{
+		"call":   call,
+		"status": strconv.Itoa(status),
+	})
+}
+
+// node returns the value of the node tag: the nodeLabel option, or the URL of the node.
+func (c *Client) node() string {
+	return c.nodeLabel(c.opts.URL)
+}
+
+// nodeLabel returns the value of the node tag of the node at the URL, which is the nodeLabel option for
+// the node of the url option.
+func (c *Client) nodeLabel(url string) string {
+	if url == c.opts.URL && c.opts.NodeLabel != "" {
+		return c.opts.NodeLabel
+	}
+	return url
+}
+
+// pushSample pushes a sample of the metric tagged with the VU tags, the node, plus the given tags.
+// Samples are dropped when there is no VU state, i.e. in the init context.
+func (c *Client) pushSample(metric *metrics.Metric, value float64, tags map[string]string) {
+	c.pushNodeSample(c.node(), metric, value, tags)
+}
+
+// pushNodeSample is pushSample for a sample of another node than the one of the url option.
+func (c *Client) pushNodeSample(node string, metric *metrics.Metric, value float64, tags map[string]string) {
+	state := c.vu.State()
+	if state == nil {
+		return
+	}
+
+	metrics.PushIfNotDone(c.vu.Context(), state.Samples, metrics.Sample{
 		TimeSeries: metrics.TimeSeries{
-			Metric: c.metrics.RequestDuration,
-			Tags:   registry.RootTagSet().With("call", call),
+			Metric: metric,
+			Tags:   state.Tags.GetCurrentValues().Tags.WithTagsFromMap(tags).With("node", node),
 		},
-		Value: float64(t / time.Millisecond),
+		Value: value,
 		Time:  time.Now(),
 	})
 }
@@ -155,14 +440,256 @@ type options struct {
 	URL      string `json:"url,omitempty"`
 	Mnemonic string `json:"mnemonic,omitempty"`
 	Accounts int    `json:"accounts,omitempty"`
+	// UseSoloKeys signs with the accounts funded in the genesis block of thor solo, so that tests against
+	// solo need neither a mnemonic nor a fund step. At most the 10 funded accounts can be used.
+	UseSoloKeys bool `json:"useSoloKeys,omitempty"`
+	// RequestIDs sends a unique X-Request-Id header with every HTTP request to the node, sets it as the
+	// requestId of the errors thrown for failed requests, and keeps the latest requests for
+	// recentRequests, so that failures can be matched with the node access logs.
+	RequestIDs bool `json:"requestIds,omitempty"`
+	// PrivateKeys are the hex encoded private keys of the accounts of the client, in place of the keys
+	// derived from the mnemonic, e.g. pre-funded accounts injected through environment variables. All of
+	// them are used unless Accounts is set.
+	PrivateKeys []string `json:"privateKeys,omitempty"`
+	// AccountsKeystore is a directory of keystore files, whose keys are the accounts of the client in
+	// place of the keys derived from the mnemonic, decrypted with KeystorePassword. The accounts are
+	// ordered by file name, and all of them are used unless Accounts is set.
+	AccountsKeystore string `json:"accountsKeystore,omitempty"`
+	KeystorePassword string `json:"keystorePassword,omitempty"`
+	// NodeLabel is the node tag of every sample, the URL when empty.
+	NodeLabel string `json:"nodeLabel,omitempty"`
+	// MetricPrefix replaces the vechain_ prefix of the metric names, e.g. "nodeA_", so that clients of
+	// different networks produce separate series even in outputs that ignore tags.
+	MetricPrefix string `json:"metricPrefix,omitempty"`
+	// URLs are the nodes transactions are submitted to, according to Routing. The node of URL, which
+	// defaults to the first one, is used for everything else, e.g. reads and the block monitor.
+	URLs []string `json:"urls,omitempty"`
+	// Routing is how submissions are distributed across URLs, either "roundRobin", "random" or "sticky"
+	// to pin each VU to a node.
+	Routing string `json:"routing,omitempty"`
+	// TrackMempool enables the vechain_mempool_accept_time metric for transactions sent by the client. At
+	// most maxMempoolTrackers transactions are looked up at once, the ones sent meanwhile are not sampled.
+	TrackMempool bool `json:"trackMempool,omitempty"`
+	// Confirmations is how many blocks deep a transaction must be before vechain_tx_confirmed
+	// increments, where 1 means included in the best block.
+	Confirmations int `json:"confirmations,omitempty"`
+	// ConfirmFinalized only counts a transaction as confirmed once its block is finalized.
+	ConfirmFinalized bool `json:"confirmFinalized,omitempty"`
+	// TrackFinality keeps tracking the transactions sent by the client after their inclusion, until their
+	// block is finalized, and records the time from submission in vechain_time_to_finality.
+	TrackFinality bool `json:"trackFinality,omitempty"`
+	// SignerScope scopes the nextSigner counter, either "global" to share it across all VUs, "vu", or
+	// "partition" to also give every VU a disjoint slice of the accounts to sign with.
+	SignerScope string `json:"signerScope,omitempty"`
+	// TagAccountIndex tags per-transaction samples with the account index of the sender.
+	TagAccountIndex bool `json:"tagAccountIndex,omitempty"`
+	// MaxInFlight caps the submitted but unmined transactions per account, unlimited when 0.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+	// InFlightMode is what a submission does when its account is at MaxInFlight, either "block" or "error".
+	InFlightMode string `json:"inFlightMode,omitempty"`
+	// Record is the path of a file every transaction accepted by the node is appended to, for replay against
+	// another node. The file is closed when the test ends.
+	Record string `json:"record,omitempty"`
+	// DrainTimeout, e.g. "30s", enables the end of test drain: submissions stop and the transactions
+	// already submitted are waited for, up to the timeout, before the final metrics are flushed.
+	DrainTimeout string `json:"drainTimeout,omitempty"`
+	// StateMetricsURL is the Prometheus endpoint of the node, e.g. http://localhost:2112/metrics,
+	// scraped for the StateMetric that vechain_state_growth follows.
+	StateMetricsURL string `json:"stateMetricsUrl,omitempty"`
+	// StateMetric is the name of the metric used as the state size indicator.
+	StateMetric string `json:"stateMetric,omitempty"`
+	// TxPoolMetrics samples the number of transactions in the pool of the node in vechain_txpool_size.
+	// The node must run with --api-enable-txpool.
+	TxPoolMetrics bool `json:"txPoolMetrics,omitempty"`
+	// LoadgenMetrics samples the resource usage of the k6 process in the vechain_loadgen_* metrics, once
+	// per process however many clients set it.
+	LoadgenMetrics bool `json:"loadgenMetrics,omitempty"`
+	// BlockSource is how the block monitor learns about new blocks, either "poll" or "ws".
+	BlockSource string `json:"blockSource,omitempty"`
+	// BlockPollInterval is how often the block monitor polls the best block, e.g. "2s", 500ms by default.
+	BlockPollInterval string `json:"blockPollInterval,omitempty"`
+	// DisableBlockMetrics turns the block monitor off, and with it the block metrics and the tracking
+	// of the transactions sent, e.g. time to mine of fire-and-forget sends and confirmations.
+	DisableBlockMetrics bool `json:"disableBlockMetrics,omitempty"`
+	// VerifySignerIsolation reports accounts that sign concurrently, or from more than one VU,
+	// in vechain_signer_conflicts.
+	VerifySignerIsolation bool `json:"verifySignerIsolation,omitempty"`
+	// DeterministicDeployers deploys the toolchain contracts from dedicated accounts derived from the
+	// mnemonic, so that their addresses are predictable across runs against a fresh node.
+	DeterministicDeployers bool `json:"deterministicDeployers,omitempty"`
+	// SequenceAccounts serializes the submissions of each account across all VUs, and rejects a
+	// transaction submitted twice from the same account.
+	SequenceAccounts bool `json:"sequenceAccounts,omitempty"`
+	// SLO declares the objectives that vechain_slo_breach is computed against.
+	SLO *sloOptions `json:"slo,omitempty"`
+	// Faucet is the faucet fundFromFaucet requests funds from, e.g. on a public testnet where no
+	// account of the client holds enough to fund the others.
+	Faucet *faucetOptions `json:"faucet,omitempty"`
 }
 
 // newOptionsFrom validates and instantiates an options struct from its map representation
-// as obtained by calling a Goja's Runtime.ExportTo.
+// as obtained by calling a Goja's Runtime.ExportTo, filling in the defaults. The errors name the
+// offending option and the expected format.
 func newOptionsFrom(argument map[string]interface{}) (*options, error) {
+	var opts options
+	if err := decodeOptions(argument, &opts); err != nil {
+		return nil, err
+	}
+
+	if opts.URL != "" {
+		if err := validateNodeURL("url", opts.URL); err != nil {
+			return nil, err
+		}
+	}
+	for i, nodeURL := range opts.URLs {
+		if err := validateNodeURL(fmt.Sprintf("urls[%d]", i), nodeURL); err != nil {
+			return nil, err
+		}
+	}
+	if opts.StateMetricsURL != "" {
+		if err := validateNodeURL("stateMetricsUrl", opts.StateMetricsURL); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, value := range map[string]int{
+		"accounts":      opts.Accounts,
+		"confirmations": opts.Confirmations,
+		"maxInFlight":   opts.MaxInFlight,
+	} {
+		if value < 0 {
+			return nil, fmt.Errorf("%s must not be negative, got %d", name, value)
+		}
+	}
+
+	if opts.URL == "" && len(opts.URLs) > 0 {
+		opts.URL = opts.URLs[0]
+	}
+	if opts.URL == "" {
+		opts.URL = "http://localhost:8669"
+	}
+
+	if len(opts.PrivateKeys) > 0 && (opts.Mnemonic != "" || opts.UseSoloKeys || opts.AccountsKeystore != "") {
+		return nil, errors.New("privateKeys cannot be set together with mnemonic, useSoloKeys or accountsKeystore")
+	}
+	if opts.AccountsKeystore != "" {
+		if opts.Mnemonic != "" || opts.UseSoloKeys {
+			return nil, errors.New("accountsKeystore cannot be set together with mnemonic or useSoloKeys")
+		}
+	} else if opts.KeystorePassword != "" {
+		return nil, errors.New("keystorePassword needs accountsKeystore")
+	}
+
+	if opts.UseSoloKeys {
+		if opts.Mnemonic != "" && opts.Mnemonic != mnemonic {
+			return nil, errors.New("useSoloKeys and mnemonic cannot be set together")
+		}
+		if opts.Accounts > soloAccounts {
+			return nil, fmt.Errorf("useSoloKeys provides %d accounts, not %d", soloAccounts, opts.Accounts)
+		}
+		opts.Mnemonic = mnemonic
+		if opts.Accounts == 0 {
+			opts.Accounts = soloAccounts
+		}
+	}
+
+	// the accounts of privateKeys and accountsKeystore replace the wallet of the mnemonic altogether
+	keysSupplied := len(opts.PrivateKeys) > 0 || opts.AccountsKeystore != ""
+	if opts.Mnemonic == "" && !keysSupplied {
+		opts.Mnemonic = mnemonic
+	}
+	if keysSupplied && opts.DeterministicDeployers {
+		return nil, errors.New("deterministicDeployers derives the deployers from the mnemonic, which privateKeys and accountsKeystore replace")
+	}
+
+	if opts.Accounts == 0 && opts.AccountsKeystore == "" && len(opts.PrivateKeys) == 0 {
+		opts.Accounts = accountAmount
+	}
+
+	if opts.Confirmations == 0 {
+		opts.Confirmations = 1
+	}
+
+	switch opts.SignerScope {
+	case "":
+		opts.SignerScope = signerScopeGlobal
+	case signerScopeGlobal, signerScopeVU, signerScopePartition:
+	default:
+		return nil, fmt.Errorf("unknown signerScope %q, expected %q, %q or %q", opts.SignerScope, signerScopeGlobal, signerScopeVU, signerScopePartition)
+	}
+
+	switch opts.InFlightMode {
+	case "":
+		opts.InFlightMode = inFlightBlock
+	case inFlightBlock, inFlightError:
+	default:
+		return nil, fmt.Errorf("unknown inFlightMode %q, expected %q or %q", opts.InFlightMode, inFlightBlock, inFlightError)
+	}
+
+	if _, err := opts.drainTimeout(); err != nil {
+		return nil, fmt.Errorf("invalid drainTimeout, expected a duration like \"30s\": %w", err)
+	}
+
+	if opts.TrackFinality && opts.DisableBlockMetrics {
+		return nil, errors.New("trackFinality needs the block monitor, which disableBlockMetrics turns off")
+	}
+	if opts.MaxInFlight > 0 && opts.DisableBlockMetrics {
+		return nil, errors.New("maxInFlight needs the block monitor to free the slots of mined transactions, which disableBlockMetrics turns off")
+	}
+
+	if (opts.StateMetricsURL == "") != (opts.StateMetric == "") {
+		return nil, errors.New("stateMetricsUrl and stateMetric must be set together")
+	}
+
+	switch opts.Routing {
+	case "":
+		opts.Routing = routingRoundRobin
+	case routingRoundRobin, routingRandom, routingSticky:
+	default:
+		return nil, fmt.Errorf("unknown routing %q, expected %q, %q or %q", opts.Routing, routingRoundRobin, routingRandom, routingSticky)
+	}
+
+	switch opts.BlockSource {
+	case "":
+		opts.BlockSource = blockSourcePoll
+	case blockSourcePoll, blockSourceWS:
+	default:
+		return nil, fmt.Errorf("unknown blockSource %q, expected %q or %q", opts.BlockSource, blockSourcePoll, blockSourceWS)
+	}
+
+	if _, err := opts.blockPollInterval(); err != nil {
+		return nil, fmt.Errorf("invalid blockPollInterval, expected a duration like \"2s\": %w", err)
+	}
+
+	if opts.MetricPrefix != "" && !metricPrefixPattern.MatchString(opts.MetricPrefix) {
+		return nil, fmt.Errorf("metricPrefix %q must only include ASCII letters, numbers or underscores and start with a letter or an underscore", opts.MetricPrefix)
+	}
+
+	if opts.SLO != nil {
+		if err := opts.SLO.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Faucet != nil {
+		if err := opts.Faucet.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Mnemonic != "" && !accounts.IsValidMnemonic(opts.Mnemonic) {
+		return nil, errors.New("mnemonic is not a valid BIP-39 phrase, expected 12 to 24 words with a valid checksum")
+	}
+
+	return &opts, nil
+}
+
+// decodeOptions decodes the map representation of a JS options object into v,
+// rejecting unknown fields.
+func decodeOptions(argument map[string]interface{}, v interface{}) error {
 	jsonStr, err := json.Marshal(argument)
 	if err != nil {
-		return nil, fmt.Errorf("unable to serialize options to JSON %w", err)
+		return fmt.Errorf("unable to serialize options to JSON %w", err)
 	}
 
 	// Instantiate a JSON decoder which will error on unknown
@@ -171,11 +698,47 @@ func newOptionsFrom(argument map[string]interface{}) (*options, error) {
 	decoder := json.NewDecoder(bytes.NewReader(jsonStr))
 	decoder.DisallowUnknownFields()
 
-	var opts options
-	err = decoder.Decode(&opts)
-	if err != nil {
-		return nil, fmt.Errorf("unable to decode options %w", err)
+	err = decoder.Decode(v)
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Errorf("%s must be %s, got a %s", typeErr.Field, jsTypeName(typeErr.Type), typeErr.Value)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return fmt.Errorf("unknown option %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return fmt.Errorf("unable to decode options %w", err)
 	}
 
-	return &opts, nil
+	return nil
+}
+
+// jsTypeName describes the JS value a Go type is decoded from.
+func jsTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Pointer:
+		return jsTypeName(t.Elem())
+	default:
+		return "an object"
+	}
+}
+
+// validateNodeURL checks that the option holds an absolute http(s) URL.
+func validateNodeURL(name, value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an http(s) URL like \"http://localhost:8669\", got %q", name, value)
+	}
+	return nil
 }
@@ -0,0 +1,214 @@
+package xk6_vechain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewOptionsFromErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		err     string
+	}{
+		{
+			name:    "unknown option",
+			options: map[string]interface{}{"acounts": 2},
+			err:     `unknown option "acounts"`,
+		},
+		{
+			name:    "wrong type",
+			options: map[string]interface{}{"accounts": "2"},
+			err:     "accounts must be an integer, got a string",
+		},
+		{
+			name:    "fractional integer",
+			options: map[string]interface{}{"confirmations": 1.5},
+			err:     "confirmations must be an integer, got a number 1.5",
+		},
+		{
+			name:    "negative accounts",
+			options: map[string]interface{}{"accounts": -1},
+			err:     "accounts must not be negative, got -1",
+		},
+		{
+			name:    "url without scheme",
+			options: map[string]interface{}{"url": "localhost:8669"},
+			err:     `url must be an http(s) URL like "http://localhost:8669", got "localhost:8669"`,
+		},
+		{
+			name:    "invalid url of the list",
+			options: map[string]interface{}{"urls": []string{"http://a:8669", "ws://b:8669"}},
+			err:     `urls[1] must be an http(s) URL like "http://localhost:8669", got "ws://b:8669"`,
+		},
+		{
+			name: "private keys with a mnemonic",
+			options: map[string]interface{}{
+				"privateKeys": []string{"0x01"},
+				"mnemonic":    mnemonic,
+			},
+			err: "privateKeys cannot be set together with mnemonic, useSoloKeys or accountsKeystore",
+		},
+		{
+			name:    "keystore password without a keystore",
+			options: map[string]interface{}{"keystorePassword": "secret"},
+			err:     "keystorePassword needs accountsKeystore",
+		},
+		{
+			name:    "too many solo accounts",
+			options: map[string]interface{}{"useSoloKeys": true, "accounts": 11},
+			err:     "useSoloKeys provides 10 accounts, not 11",
+		},
+		{
+			name: "deterministic deployers with private keys",
+			options: map[string]interface{}{
+				"privateKeys":            []string{"0x01"},
+				"deterministicDeployers": true,
+			},
+			err: "deterministicDeployers derives the deployers from the mnemonic",
+		},
+		{
+			name:    "unknown signer scope",
+			options: map[string]interface{}{"signerScope": "scenario"},
+			err:     `unknown signerScope "scenario"`,
+		},
+		{
+			name:    "max in flight without block metrics",
+			options: map[string]interface{}{"maxInFlight": 2, "disableBlockMetrics": true},
+			err:     "maxInFlight needs the block monitor",
+		},
+		{
+			name:    "state metric without its url",
+			options: map[string]interface{}{"stateMetric": "state_size"},
+			err:     "stateMetricsUrl and stateMetric must be set together",
+		},
+		{
+			name:    "zero block poll interval",
+			options: map[string]interface{}{"blockPollInterval": "0s"},
+			err:     "blockPollInterval must be greater than 0",
+		},
+		{
+			name:    "metric prefix starting with a digit",
+			options: map[string]interface{}{"metricPrefix": "1node_"},
+			err:     `metricPrefix "1node_" must only include ASCII letters`,
+		},
+		{
+			name:    "invalid mnemonic",
+			options: map[string]interface{}{"mnemonic": "not a mnemonic"},
+			err:     "mnemonic is not a valid BIP-39 phrase",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newOptionsFrom(tt.options)
+			if err == nil {
+				t.Fatalf("expected an error containing %q, got none", tt.err)
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected an error containing %q, got %q", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestNewOptionsFromDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		url      string
+		mnemonic string
+		accounts int
+	}{
+		{
+			name:     "empty",
+			options:  map[string]interface{}{},
+			url:      "http://localhost:8669",
+			mnemonic: mnemonic,
+			accounts: accountAmount,
+		},
+		{
+			name:     "url from the list",
+			options:  map[string]interface{}{"urls": []string{"http://a:8669", "http://b:8669"}},
+			url:      "http://a:8669",
+			mnemonic: mnemonic,
+			accounts: accountAmount,
+		},
+		{
+			name:     "solo keys",
+			options:  map[string]interface{}{"useSoloKeys": true},
+			url:      "http://localhost:8669",
+			mnemonic: mnemonic,
+			accounts: soloAccounts,
+		},
+		{
+			name:     "private keys replace the mnemonic",
+			options:  map[string]interface{}{"privateKeys": []string{"0x01", "0x02"}},
+			url:      "http://localhost:8669",
+			mnemonic: "",
+			accounts: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := newOptionsFrom(tt.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.URL != tt.url {
+				t.Errorf("url: expected %q, got %q", tt.url, opts.URL)
+			}
+			if opts.Mnemonic != tt.mnemonic {
+				t.Errorf("mnemonic: expected %q, got %q", tt.mnemonic, opts.Mnemonic)
+			}
+			if opts.Accounts != tt.accounts {
+				t.Errorf("accounts: expected %d, got %d", tt.accounts, opts.Accounts)
+			}
+			if opts.Confirmations != 1 {
+				t.Errorf("confirmations: expected 1, got %d", opts.Confirmations)
+			}
+			if opts.SignerScope != signerScopeGlobal {
+				t.Errorf("signerScope: expected %q, got %q", signerScopeGlobal, opts.SignerScope)
+			}
+		})
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	var opts struct {
+		Start   int      `json:"start,omitempty"`
+		Amount  string   `json:"amount,omitempty"`
+		Enabled *bool    `json:"enabled,omitempty"`
+		Names   []string `json:"names,omitempty"`
+	}
+
+	tests := []struct {
+		name    string
+		options map[string]interface{}
+		err     string
+	}{
+		{name: "nil", options: nil},
+		{name: "valid", options: map[string]interface{}{"start": 2, "enabled": false, "names": []string{"a"}}},
+		{name: "unknown option", options: map[string]interface{}{"stat": 2}, err: `unknown option "stat"`},
+		{name: "string for an integer", options: map[string]interface{}{"start": "2"}, err: "start must be an integer, got a string"},
+		{name: "number for a string", options: map[string]interface{}{"amount": 1}, err: "amount must be a string, got a number"},
+		{name: "string for a boolean", options: map[string]interface{}{"enabled": "yes"}, err: "enabled must be a boolean, got a string"},
+		{name: "string for an array", options: map[string]interface{}{"names": "a"}, err: "names must be an array, got a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeOptions(tt.options, &opts)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("expected the error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
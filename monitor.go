@@ -0,0 +1,438 @@
+package xk6_vechain
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// reportedBlocksDepth is how many blocks behind the best block are kept for deduplication.
+	reportedBlocksDepth = 1000
+	// defaultBlockPollInterval is how often the best block is polled while the node is healthy, unless
+	// blockPollInterval is set.
+	defaultBlockPollInterval = 500 * time.Millisecond
+	// maxPollBackoff caps the poll interval while the node keeps failing.
+	maxPollBackoff = 30 * time.Second
+)
+
+// reportedBlock identifies a block whose metrics were reported.
+type reportedBlock struct {
+	url    string
+	number uint64
+}
+
+var (
+	// blocks holds the reported blocks of every node, so that each block is reported once across all clients.
+	blocks sync.Map // reportedBlock -> struct{}
+	// restSeen holds when each block was first seen by polling, to compare with subscription deliveries.
+	restSeen sync.Map // reportedBlock -> time.Time
+	// wsSeen holds when each block was delivered by the subscription, to compare with polling.
+	wsSeen sync.Map // reportedBlock -> time.Time
+)
+
+// poll polls the best block of the node and reports the block metrics for every new block, through the
+// reporter of the poller. With the "ws" blockSource the blocks are pushed by the node instead, while
+// the best block is still polled to compare the deliveries, and polling only feeds the monitor once
+// the subscription fails.
+// Consecutive failures back off exponentially up to maxPollBackoff and increment vechain_monitor_errors,
+// and the recovery is logged along with the length of the outage. It returns once the poller is stopped.
+func (p *blockPoller) poll(ctx context.Context) {
+	var (
+		prev         *client.Block
+		failures     int
+		failingSince time.Time
+	)
+
+	if c := p.reporter(); c != nil && c.opts.BlockSource == blockSourceWS {
+		restCtx, stopREST := context.WithCancel(ctx)
+		go p.trackRESTDelivery(restCtx)
+		prev = p.subscribe(ctx)
+		stopREST()
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	for {
+		c := p.reporter()
+		if c == nil {
+			return
+		}
+
+		block, err := c.thor.Blocks.Best()
+		if err != nil {
+			if failures == 0 {
+				failingSince = time.Now()
+				c.log().WithField("url", p.url).WithError(err).Warn("block monitor failed to fetch the best block")
+			}
+			failures++
+			c.pushSample(c.metrics.MonitorErrors, 1, nil)
+			if !sleep(ctx, pollBackoff(c.pollInterval(), failures)) {
+				return
+			}
+			continue
+		}
+
+		if failures > 0 {
+			c.log().WithFields(logrus.Fields{"url": p.url, "failures": failures, "outage": time.Since(failingSince)}).
+				Info("block monitor recovered")
+			failures = 0
+		}
+
+		c.observeRESTDelivery(block.Number, time.Now())
+		prev = c.onBlock(prev, block)
+
+		if !sleep(ctx, c.pollInterval()) {
+			return
+		}
+	}
+}
+
+// subscribe feeds the monitor from the block subscription, resubscribing through another client when
+// the client subscribed is closed. It returns the last block seen once the subscription fails or the
+// poller is stopped.
+func (p *blockPoller) subscribe(ctx context.Context) *client.Block {
+	var prev *client.Block
+	for c := p.reporter(); c != nil && c.opts.BlockSource == blockSourceWS; c = p.reporter() {
+		var err error
+		prev, err = c.subscribeBlocks()
+		if ctx.Err() != nil {
+			return prev
+		}
+		if c.ctx.Err() != nil {
+			// the client subscribed was closed, resubscribe through another one
+			continue
+		}
+		c.log().WithField("url", p.url).WithError(err).Warn("block subscription failed, falling back to polling")
+		c.pushSample(c.metrics.MonitorErrors, 1, nil)
+		break
+	}
+	return prev
+}
+
+// trackRESTDelivery polls the best block while the subscription feeds the monitor, only to record when
+// each block is first seen over REST for vechain_ws_delivery_latency. Failures are left to the monitor.
+func (p *blockPoller) trackRESTDelivery(ctx context.Context) {
+	for {
+		c := p.reporter()
+		if c == nil {
+			return
+		}
+		if block, err := c.thor.Blocks.Best(); err == nil {
+			c.observeRESTDelivery(block.Number, time.Now())
+		}
+		if !sleep(ctx, c.pollInterval()) {
+			return
+		}
+	}
+}
+
+// sleep waits for the duration, returning false when the context is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// sleep waits for the duration, returning false when the client is closed first.
+func (c *Client) sleep(d time.Duration) bool {
+	return sleep(c.ctx, d)
+}
+
+// sampleEvery calls sample every interval while the VU of the client is running, until the client is
+// closed. The slot holds the index of the last sampled interval, so that the clients sharing it sample
+// each interval once.
+func (c *Client) sampleEvery(interval time.Duration, slot *atomic.Int64, sample func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if c.vu.State() == nil {
+			continue
+		}
+		current := time.Now().UnixNano() / int64(interval)
+		last := slot.Load()
+		if current <= last || !slot.CompareAndSwap(last, current) {
+			continue
+		}
+		sample()
+	}
+}
+
+// onBlock reports the block when it is newer than the previous block, and returns the latest of the two.
+// The transactions of every client of the node are tracked, while the block metrics are pushed once.
+// A block at the height of the previous one with another ID is a switch of the head, which is checked
+// for a reorg but not reported again. On a reorg, the transactions of the orphaned blocks are tracked
+// as pending again, and the blocks of the new chain are fed to the tracker from the fork on.
+func (c *Client) onBlock(prev, block *client.Block) *client.Block {
+	if prev != nil && (block.Number < prev.Number || block.ID == prev.ID) {
+		return prev
+	}
+	reorged, fork := c.detectReorg(block)
+	if prev == nil {
+		return block
+	}
+
+	from := prev.Number
+	if reorged && fork < from {
+		from = fork
+	}
+	inclusion := make(map[uint64]*blockInclusion)
+	for _, subscriber := range pollerFor(c.opts.URL).subscribers() {
+		if reorged {
+			subscriber.tracker.orphan(fork)
+		}
+		if block.Number > from {
+			subscriber.trackBlocks(from, block, inclusion)
+		}
+	}
+	c.reportInclusion(inclusion)
+	if block.Number > prev.Number {
+		c.reportBlock(prev, block)
+	}
+	return block
+}
+
+// pollBackoff returns the poll interval after the given number of consecutive failures.
+func pollBackoff(base time.Duration, failures int) time.Duration {
+	interval := base
+	for i := 0; i < failures && interval < maxPollBackoff; i++ {
+		interval *= 2
+	}
+	if interval > maxPollBackoff {
+		interval = maxPollBackoff
+	}
+	return interval
+}
+
+// blockPollInterval returns how often the best block is polled.
+func (o *options) blockPollInterval() (time.Duration, error) {
+	if o.BlockPollInterval == "" {
+		return defaultBlockPollInterval, nil
+	}
+	interval, err := time.ParseDuration(o.BlockPollInterval)
+	if err != nil {
+		return 0, err
+	}
+	if interval <= 0 {
+		return 0, errors.New("blockPollInterval must be greater than 0")
+	}
+	return interval, nil
+}
+
+// pollInterval returns how often the block monitor polls the best block for the client.
+func (c *Client) pollInterval() time.Duration {
+	interval, _ := c.opts.blockPollInterval()
+	return interval
+}
+
+// reportBlock pushes the block metrics for a new best block, once per node across all clients.
+func (c *Client) reportBlock(prev, block *client.Block) {
+	blockTimestampDiff := time.Unix(int64(block.Timestamp), 0).Sub(time.Unix(int64(prev.Timestamp), 0))
+	tps := float64(len(block.Transactions)) / float64(blockTimestampDiff.Seconds())
+	stats := statsFor(c.opts.URL)
+	stats.setTPS(tps)
+	utilization := float64(block.GasUsed) / float64(block.GasLimit) * 100
+	stats.setUtilization(utilization)
+
+	rootTS := metrics.NewRegistry().RootTagSet().With("node", c.node())
+	signerTS := rootTS.With("signer", block.Signer.Hex())
+	if c.vu != nil && c.vu.State() != nil {
+		if _, loaded := blocks.LoadOrStore(reportedBlock{url: c.opts.URL, number: block.Number}, struct{}{}); loaded {
+			// We already have a block number for this client, so we can skip this
+			return
+		}
+
+		stats.observeBlock(block.Number, len(block.Transactions))
+		c.evictReportedBlocks(block.Number)
+		c.reportTrackedItems()
+		c.reportFinalityLag(block.Number)
+		c.detectMissedSlots(prev, block)
+
+		samples := []metrics.Sample{
+			{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.Block,
+					Tags: rootTS.WithTagsFromMap(map[string]string{
+						"transactions": strconv.Itoa(len(block.Transactions)),
+						"gas_used":     strconv.Itoa(int(block.GasUsed)),
+						"gas_limit":    strconv.Itoa(int(block.GasLimit)),
+						"signer":       block.Signer.Hex(),
+					}),
+				},
+				Value: float64(block.Number),
+				Time:  time.Now(),
+			},
+			{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.GasUsed,
+					Tags: rootTS.WithTagsFromMap(map[string]string{
+						"block":  strconv.Itoa(int(block.Number)),
+						"signer": block.Signer.Hex(),
+					}),
+				},
+				Value: float64(block.GasUsed),
+				Time:  time.Now(),
+			},
+			{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.BlockUtilization,
+					Tags:   signerTS,
+				},
+				Value: utilization,
+				Time:  time.Now(),
+			},
+			{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.TPS,
+					Tags:   signerTS,
+				},
+				Value: tps,
+				Time:  time.Now(),
+			},
+			{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.BlockTime,
+					Tags: rootTS.WithTagsFromMap(map[string]string{
+						"block_timestamp_diff": blockTimestampDiff.String(),
+						"signer":               block.Signer.Hex(),
+					}),
+				},
+				Value: float64(blockTimestampDiff.Milliseconds()),
+				Time:  time.Now(),
+			},
+		}
+
+		if contents, err := c.blockContents(block); err == nil {
+			samples = append(samples, metrics.Sample{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.CPS,
+					Tags:   signerTS,
+				},
+				Value: float64(contents.clauses) / blockTimestampDiff.Seconds(),
+				Time:  time.Now(),
+			})
+			for origin, txs := range map[string]int{"ours": contents.ours, "foreign": contents.foreign} {
+				samples = append(samples, metrics.Sample{
+					TimeSeries: metrics.TimeSeries{
+						Metric: c.metrics.OriginTxs,
+						Tags:   signerTS.With("origin", origin),
+					},
+					Value: float64(txs),
+					Time:  time.Now(),
+				})
+			}
+			for _, coef := range contents.foreignCoefs {
+				samples = append(samples, metrics.Sample{
+					TimeSeries: metrics.TimeSeries{
+						Metric: c.metrics.ForeignGasPriceCoef,
+						Tags:   signerTS,
+					},
+					Value: float64(coef),
+					Time:  time.Now(),
+				})
+			}
+			samples = append(samples, metrics.Sample{
+				TimeSeries: metrics.TimeSeries{
+					Metric: c.metrics.OwnTPS,
+					Tags:   signerTS,
+				},
+				Value: float64(contents.ours) / blockTimestampDiff.Seconds(),
+				Time:  time.Now(),
+			})
+		}
+
+		metrics.PushIfNotDone(c.vu.Context(), c.vu.State().Samples, metrics.ConnectedSamples{
+			Samples: samples,
+		})
+	}
+}
+
+// blockContents is the breakdown of the transactions included in a block.
+type blockContents struct {
+	clauses int
+	// ours counts the transactions sent from managed accounts, foreign counts the rest.
+	ours    int
+	foreign int
+	// foreignCoefs holds the gas price coefficient of every foreign transaction.
+	foreignCoefs []uint64
+}
+
+// blockContents returns the number of clauses included in the block, and the number of transactions
+// by origin. The expanded block is only fetched when the block contains transactions.
+func (c *Client) blockContents(block *client.Block) (blockContents, error) {
+	var contents blockContents
+	if len(block.Transactions) == 0 {
+		return contents, nil
+	}
+
+	expanded, err := c.thor.Blocks.Expanded(block.ID.Hex())
+	if err != nil {
+		return contents, err
+	}
+
+	for _, tx := range expanded.Transactions {
+		contents.clauses += len(tx.Clauses)
+		if _, ok := c.managed[tx.Origin]; ok {
+			contents.ours++
+		} else {
+			contents.foreign++
+			contents.foreignCoefs = append(contents.foreignCoefs, tx.GasPriceCoef)
+		}
+	}
+	return contents, nil
+}
+
+// evictReportedBlocks forgets the reported and polled blocks of the node that are too deep to be polled again.
+func (c *Client) evictReportedBlocks(best uint64) {
+	if best < reportedBlocksDepth {
+		return
+	}
+	for _, seen := range []*sync.Map{&blocks, &restSeen, &wsSeen} {
+		seen.Range(func(key, _ any) bool {
+			if reported := key.(reportedBlock); reported.url == c.opts.URL && reported.number < best-reportedBlocksDepth {
+				seen.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// reportTrackedItems reports the size of the internal caches of the node in vechain_internal_tracked_items,
+// so that soak tests can tell whether the extension itself is growing.
+func (c *Client) reportTrackedItems() {
+	stats := statsFor(c.opts.URL)
+
+	reported := 0
+	blocks.Range(func(key, _ any) bool {
+		if key.(reportedBlock).url == c.opts.URL {
+			reported++
+		}
+		return true
+	})
+
+	for cache, size := range map[string]int64{
+		"blocks":   int64(reported),
+		"pending":  stats.pendingTxs.Load(),
+		"included": stats.includedTxs.Load(),
+		"registry": int64(registryFor(c.opts.URL).size()),
+	} {
+		c.pushSample(c.metrics.TrackedItems, float64(size), map[string]string{"cache": cache})
+	}
+}
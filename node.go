@@ -0,0 +1,48 @@
+package xk6_vechain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// getJSON performs a GET request against the node and decodes the JSON response into v.
+// It is used for endpoints, or fields, that the SDK does not expose.
+func (c *Client) getJSON(path string, v interface{}) error {
+	res, err := c.http.Get(strings.TrimSuffix(c.opts.URL, "/") + path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s failed with status %d: %s", path, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if strings.TrimSpace(string(body)) == "null" {
+		return fmt.Errorf("GET %s: resource not found", path)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// BlockRaw returns the block at the given revision exactly as the node serves it, including
+// fields that the SDK block type drops. The revision defaults to "best".
+func (c *Client) BlockRaw(revision string) (map[string]interface{}, error) {
+	if revision == "" {
+		revision = "best"
+	}
+
+	block := make(map[string]interface{})
+	if err := c.getJSON("/blocks/"+revision, &block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
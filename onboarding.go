@@ -0,0 +1,59 @@
+package xk6_vechain
+
+import (
+	"fmt"
+
+	"github.com/darrenvechain/xk6-vechain/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// generateOptions configures generateAccounts.
+type generateOptions struct {
+	// Fund is the amount of VET and of VTHO, as hex, sent to every generated account. They are not
+	// funded when empty.
+	Fund string `json:"fund,omitempty"`
+	// Funders is how many of the accounts of the client, from the first one, fund the generated
+	// accounts, all of them when not set.
+	Funders int `json:"funders,omitempty"`
+}
+
+// GenerateAccounts returns the amount of fresh random accounts, as the accounts module does, and funds
+// them from the accounts of the client when fund is set, waiting until the funding is mined. It lets
+// scripts onboard never-before-seen users at runtime.
+func (c *Client) GenerateAccounts(amount int, options map[string]interface{}) ([]accounts.GeneratedAccount, error) {
+	var opts generateOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Funders < 0 || opts.Funders > len(c.managers) {
+		return nil, fmt.Errorf("funders must be between 1 and %d", len(c.managers))
+	}
+	if opts.Funders == 0 {
+		opts.Funders = len(c.managers)
+	}
+
+	generated, err := accounts.New(amount)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Fund == "" {
+		return generated, nil
+	}
+
+	value, err := parseHexAmount(opts.Fund)
+	if err != nil {
+		return nil, err
+	}
+	transfers := make([]transfer, len(generated))
+	for i, account := range generated {
+		transfers[i] = transfer{to: common.HexToAddress(account.Address), vet: value, vtho: value}
+	}
+	plan, err := c.planTransfers(transfers, opts.Funders)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.fund(plan); err != nil {
+		return nil, fmt.Errorf("failed to fund the generated accounts: %w", err)
+	}
+	return generated, nil
+}
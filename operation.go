@@ -0,0 +1,53 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/sobek"
+	"go.k6.io/k6/metrics"
+)
+
+// operationTag is the tag that groups the samples pushed during an operation.
+const operationTag = "operation"
+
+// WithOperation calls fn as the named operation, e.g. client.withOperation("checkout", () => { ... }),
+// so that a business flow made of several chain calls is measured as a unit. Every sample pushed while
+// fn runs, by the client and by the other modules of the VU, is tagged with the operation. The start is
+// counted in vechain_operations and the time until fn returns is recorded in vechain_operation_duration,
+// tagged with status ok or failed. Nested operations tag their samples with the innermost name. It
+// returns what fn returns, and rethrows what it throws.
+func (c *Client) WithOperation(name string, fn sobek.Callable) (sobek.Value, error) {
+	if name == "" {
+		return nil, errors.New("the operation needs a name")
+	}
+	if fn == nil {
+		return nil, errors.New("the operation needs a function")
+	}
+	state := c.vu.State()
+	if state == nil {
+		return nil, errors.New("withOperation can only be called from a VU iteration")
+	}
+
+	outer, nested := state.Tags.GetCurrentValues().Tags.Get(operationTag)
+	state.Tags.Modify(func(tagsAndMeta *metrics.TagsAndMeta) {
+		tagsAndMeta.SetTag(operationTag, name)
+	})
+	defer state.Tags.Modify(func(tagsAndMeta *metrics.TagsAndMeta) {
+		if nested {
+			tagsAndMeta.SetTag(operationTag, outer)
+		} else {
+			tagsAndMeta.DeleteTag(operationTag)
+		}
+	})
+
+	c.pushSample(c.metrics.Operations, 1, nil)
+	started := time.Now()
+	result, err := fn(sobek.Undefined())
+	status := "ok"
+	if err != nil {
+		status = "failed"
+	}
+	c.pushSample(c.metrics.OperationDuration, metrics.D(time.Since(started)), map[string]string{"status": status})
+	return result, err
+}
@@ -0,0 +1,138 @@
+package xk6_vechain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// nodeProbeInterval is how often the node is probed while waiting for it to go down or recover.
+	nodeProbeInterval = 250 * time.Millisecond
+	// nodeProbeTimeout bounds every probe, so that a node that accepts connections but never answers
+	// counts as down.
+	nodeProbeTimeout = 2 * time.Second
+	// defaultNodeWaitTimeout is how long awaitNodeDown and awaitNodeRecovery wait unless a timeout is given.
+	defaultNodeWaitTimeout = 5 * time.Minute
+)
+
+// outages holds when each node URL was seen going down by awaitNodeDown, so that any VU can await its recovery.
+var outages sync.Map // url -> time.Time
+
+// nodeWaitOptions configures awaitNodeDown and awaitNodeRecovery.
+type nodeWaitOptions struct {
+	// Timeout is how long to wait, e.g. "2m".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// NodeRecovery is the outcome of awaitNodeRecovery. Downtime is how long the node was unreachable, in ms,
+// and Block is the best block once it answered again.
+type NodeRecovery struct {
+	Downtime float64 `js:"downtime"`
+	Block    uint64  `js:"block"`
+}
+
+// AwaitNodeDown blocks until the node stops answering, e.g. once a restart was triggered, and marks the
+// start of the outage for awaitNodeRecovery. It fails when the node is still up after the timeout.
+func (c *Client) AwaitNodeDown(options map[string]interface{}) error {
+	timeout, err := nodeWaitTimeout(options)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := c.probeNode(); err != nil {
+			outages.Store(c.opts.URL, time.Now())
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("node %s is still up after %s", c.opts.URL, timeout)
+		}
+		if !c.sleep(nodeProbeInterval) {
+			return c.ctx.Err()
+		}
+	}
+}
+
+// AwaitNodeRecovery blocks until the node answers again and records how long it was down in
+// vechain_node_downtime, from the moment awaitNodeDown saw it go down, or from the first failed probe
+// otherwise. It returns immediately when the node never went down, and fails when the node is still
+// down after the timeout.
+func (c *Client) AwaitNodeRecovery(options map[string]interface{}) (*NodeRecovery, error) {
+	timeout, err := nodeWaitTimeout(options)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		number, err := c.probeNode()
+		if err == nil {
+			since, down := outages.LoadAndDelete(c.opts.URL)
+			if !down {
+				return &NodeRecovery{Block: number}, nil
+			}
+			downtime := time.Since(since.(time.Time))
+			c.pushSample(c.metrics.NodeDowntime, metrics.D(downtime), nil)
+			return &NodeRecovery{Downtime: metrics.D(downtime), Block: number}, nil
+		}
+
+		outages.LoadOrStore(c.opts.URL, time.Now())
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("node %s is still down after %s: %w", c.opts.URL, timeout, err)
+		}
+		if !c.sleep(nodeProbeInterval) {
+			return nil, c.ctx.Err()
+		}
+	}
+}
+
+// probeNode requests the best block of the node within nodeProbeTimeout, and returns its number.
+func (c *Client) probeNode() (uint64, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, nodeProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.opts.URL, "/")+"/blocks/best", nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	var block struct {
+		Number uint64 `json:"number"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&block); err != nil {
+		return 0, err
+	}
+	return block.Number, nil
+}
+
+// nodeWaitTimeout returns the timeout of the awaitNodeDown and awaitNodeRecovery options.
+func nodeWaitTimeout(options map[string]interface{}) (time.Duration, error) {
+	var opts nodeWaitOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return 0, err
+	}
+	if opts.Timeout == "" {
+		return defaultNodeWaitTimeout, nil
+	}
+	timeout, err := time.ParseDuration(opts.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout: %w", err)
+	}
+	return timeout, nil
+}
@@ -0,0 +1,115 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/darrenvechain/xk6-vechain/random"
+	"go.k6.io/k6/lib"
+)
+
+// signerScopePartition gives every VU its own counter over a disjoint slice of the accounts.
+const signerScopePartition = "partition"
+
+// accountPartition is the slice of the accounts a VU signs with.
+type accountPartition struct {
+	start, count int
+}
+
+// VUAccount is the account of a VU: the first account of its slice, with the size of the slice.
+type VUAccount struct {
+	Index   int    `js:"index"`
+	Address string `js:"address"`
+	Count   int    `js:"count"`
+}
+
+// Account returns the account of the VU. With the "partition" signerScope, every VU gets a disjoint
+// slice of the accounts, by its ID, which nextSigner and the default signers rotate within, and the
+// account is the first of the slice. Otherwise every VU shares all the accounts.
+func (c *Client) Account() (*VUAccount, error) {
+	partition, err := c.partition()
+	if err != nil {
+		return nil, err
+	}
+	return &VUAccount{
+		Index:   partition.start,
+		Address: c.managers[partition.start].Address().Hex(),
+		Count:   partition.count,
+	}, nil
+}
+
+// partition returns the slice of the accounts the VU signs with, all of them unless signerScope is
+// "partition". Outside of the iterations, e.g. in setup, the VU has no ID and gets all the accounts.
+// It fails when the accounts are too few to give every VU its own, rather than sharing them.
+func (c *Client) partition() (accountPartition, error) {
+	all := accountPartition{start: 0, count: len(c.managers)}
+	if c.opts.SignerScope != signerScopePartition {
+		return all, nil
+	}
+	if partition := c.partitioned.Load(); partition != nil {
+		return *partition, nil
+	}
+	state := c.vu.State()
+	if state == nil || state.VUIDGlobal == 0 {
+		return all, nil
+	}
+
+	partition, err := partitionAccounts(len(c.managers), plannedVUs(state.Options), state.VUIDGlobal)
+	if err != nil {
+		return accountPartition{}, err
+	}
+	c.partitioned.Store(&partition)
+	return partition, nil
+}
+
+// partitionAccounts splits the accounts evenly between the VUs and returns the slice of the VU, given
+// by its 1-based ID. It fails when there are fewer accounts than VUs, and for a VU beyond the planned
+// ones, which could only get a slice overlapping the slice of another VU.
+func partitionAccounts(accounts int, vus, vuID uint64) (accountPartition, error) {
+	if vus == 0 {
+		return accountPartition{}, errors.New("the accounts cannot be partitioned, the number of VUs of the test is unknown")
+	}
+	if vuID == 0 || vuID > vus {
+		return accountPartition{}, fmt.Errorf("VU %d is beyond the %d VUs the accounts are partitioned between", vuID, vus)
+	}
+	per := accounts / int(vus)
+	if per == 0 {
+		return accountPartition{}, fmt.Errorf("%d accounts cannot be partitioned between %d VUs, set accounts to at least the number of VUs", accounts, vus)
+	}
+	return accountPartition{start: int(vuID-1) * per, count: per}, nil
+}
+
+// plannedVUs returns the number of VUs of the whole test, across all scenarios and instances.
+func plannedVUs(options lib.Options) uint64 {
+	tuple, err := lib.NewExecutionTuple(nil, nil)
+	if err != nil {
+		return 0
+	}
+	return lib.GetMaxPossibleVUs(options.Scenarios.GetFullExecutionRequirements(tuple))
+}
+
+// indexes returns the account index of every account of the partition.
+func (p accountPartition) indexes() []int {
+	indexes := make([]int, p.count)
+	for i := range indexes {
+		indexes[i] = p.start + i
+	}
+	return indexes
+}
+
+// randomSigner returns a random account index of the slice of the VU.
+func (c *Client) randomSigner() (int, error) {
+	partition, err := c.partition()
+	if err != nil {
+		return 0, err
+	}
+	return partition.start + random.Intn(partition.count), nil
+}
+
+// signerOrRandom returns the signer when it is set, or else a random account index of the slice of the VU.
+func (c *Client) signerOrRandom(signer *int) (int, error) {
+	if signer != nil {
+		return *signer, nil
+	}
+	return c.randomSigner()
+}
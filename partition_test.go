@@ -0,0 +1,57 @@
+package xk6_vechain
+
+import "testing"
+
+func TestPartitionAccounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		accounts  int
+		vus, vuID uint64
+		partition accountPartition
+		err       string
+	}{
+		{name: "single VU", accounts: 10, vus: 1, vuID: 1, partition: accountPartition{start: 0, count: 10}},
+		{name: "first VU", accounts: 10, vus: 2, vuID: 1, partition: accountPartition{start: 0, count: 5}},
+		{name: "last VU", accounts: 10, vus: 2, vuID: 2, partition: accountPartition{start: 5, count: 5}},
+		{name: "remainder left out", accounts: 10, vus: 3, vuID: 3, partition: accountPartition{start: 6, count: 3}},
+		{name: "one account per VU", accounts: 4, vus: 4, vuID: 4, partition: accountPartition{start: 3, count: 1}},
+		{
+			name:     "unknown VUs",
+			accounts: 10, vus: 0, vuID: 2,
+			err: "the accounts cannot be partitioned, the number of VUs of the test is unknown",
+		},
+		{
+			name:     "VU beyond the planned ones",
+			accounts: 12, vus: 2, vuID: 3,
+			err: "VU 3 is beyond the 2 VUs the accounts are partitioned between",
+		},
+		{
+			name:     "fewer accounts than VUs",
+			accounts: 3, vus: 4, vuID: 1,
+			err: "3 accounts cannot be partitioned between 4 VUs, set accounts to at least the number of VUs",
+		},
+		{
+			name:     "no accounts",
+			accounts: 0, vus: 1, vuID: 1,
+			err: "0 accounts cannot be partitioned between 1 VUs, set accounts to at least the number of VUs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partition, err := partitionAccounts(tt.accounts, tt.vus, tt.vuID)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected the error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if partition != tt.partition {
+				t.Fatalf("expected %+v, got %+v", tt.partition, partition)
+			}
+		})
+	}
+}
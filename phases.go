@@ -0,0 +1,145 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// phase is a single step of a staircase load profile.
+type phase struct {
+	// TPS is the number of transactions submitted per second during the phase.
+	TPS float64 `json:"tps"`
+	// Duration is how long the phase lasts, e.g. "2m".
+	Duration string `json:"duration"`
+}
+
+// phasesOptions configures runPhases.
+type phasesOptions struct {
+	// Contract is the toolchain contract the transactions call.
+	Contract string `json:"contract"`
+	// MaxInFlight caps the number of submissions in progress at once.
+	MaxInFlight int `json:"maxInFlight,omitempty"`
+}
+
+// PhaseResult is the outcome of a single phase.
+type PhaseResult struct {
+	TPS         float64 `js:"tps"`
+	Duration    string  `js:"duration"`
+	Sent        uint64  `js:"sent"`
+	Failed      uint64  `js:"failed"`
+	Skipped     uint64  `js:"skipped"`
+	AchievedTPS float64 `js:"achievedTps"`
+}
+
+// RunPhases submits toolchain transactions following a staircase load profile, blocking until every
+// phase has run. Submissions are scheduled against the start of the phase rather than the previous
+// submission, so that pacing does not drift. A submission is skipped, rather than delayed, when
+// maxInFlight submissions are already in progress. The target of the current phase is reported in
+// vechain_target_tps. The results are counted once every submission has completed, including those of
+// a phase still in progress when the next one starts.
+func (c *Client) RunPhases(phases []map[string]interface{}, options map[string]interface{}) ([]PhaseResult, error) {
+	var opts phasesOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Contract == "" {
+		return nil, errors.New("contract is required")
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultInjectorInFlight
+	}
+
+	steps := make([]phase, len(phases))
+	durations := make([]time.Duration, len(phases))
+	for i, argument := range phases {
+		if err := decodeOptions(argument, &steps[i]); err != nil {
+			return nil, fmt.Errorf("invalid phase at index %d: %w", i, err)
+		}
+		if steps[i].TPS <= 0 {
+			return nil, fmt.Errorf("invalid phase at index %d: tps must be greater than 0", i)
+		}
+		duration, err := time.ParseDuration(steps[i].Duration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid phase at index %d: invalid duration %q", i, steps[i].Duration)
+		}
+		durations[i] = duration
+	}
+
+	// phaseCounts counts the submissions of a phase, until every submission has completed
+	type phaseCounts struct {
+		sent, failed, skipped atomic.Uint64
+	}
+
+	var (
+		wg       sync.WaitGroup
+		inFlight = make(chan struct{}, opts.MaxInFlight)
+		counts   = make([]phaseCounts, len(steps))
+		ctx      = c.vu.Context()
+	)
+
+	for i, step := range steps {
+		sent, failed, skipped := &counts[i].sent, &counts[i].failed, &counts[i].skipped
+		interval := time.Duration(float64(time.Second) / step.TPS)
+		started := time.Now()
+		end := started.Add(durations[i])
+
+		c.pushSample(c.metrics.TargetTPS, step.TPS, map[string]string{"phase": strconv.Itoa(i)})
+
+		for n := 0; ; n++ {
+			next := started.Add(time.Duration(n) * interval)
+			if !next.Before(end) {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return nil, ctx.Err()
+			case <-time.After(time.Until(next)):
+			}
+
+			select {
+			case inFlight <- struct{}{}:
+			default:
+				skipped.Add(1)
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-inFlight }()
+				if _, err := c.SendToolchainTransaction(opts.Contract, nil); err != nil {
+					failed.Add(1)
+					return
+				}
+				sent.Add(1)
+			}()
+		}
+
+		// wait out the phase, as the last submission is scheduled before its end
+		if !sleep(ctx, time.Until(end)) {
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+	}
+
+	wg.Wait()
+	results := make([]PhaseResult, len(steps))
+	for i, step := range steps {
+		sent := counts[i].sent.Load()
+		results[i] = PhaseResult{
+			TPS:         step.TPS,
+			Duration:    step.Duration,
+			Sent:        sent,
+			Failed:      counts[i].failed.Load(),
+			Skipped:     counts[i].skipped.Load(),
+			AchievedTPS: float64(sent) / durations[i].Seconds(),
+		}
+	}
+	return results, nil
+}
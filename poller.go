@@ -0,0 +1,141 @@
+package xk6_vechain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// blockPoller monitors the blocks of a node on behalf of every client of the node, so that the node is
+// polled once however many VUs there are. It runs while at least one client holds it.
+type blockPoller struct {
+	url      string
+	settings pollerSettings
+	mu       sync.Mutex
+	clients  []*Client
+	cancel   context.CancelFunc
+}
+
+// pollerSettings are the options of a client that the poller of its node applies to every client, so
+// that the clients of a node must agree on them.
+type pollerSettings struct {
+	blockSource  string
+	pollInterval time.Duration
+	metricPrefix string
+}
+
+// pollerSettings returns the poller settings of the client.
+func (c *Client) pollerSettings() pollerSettings {
+	prefix := c.opts.MetricPrefix
+	if prefix == "" {
+		prefix = defaultMetricPrefix
+	}
+	return pollerSettings{
+		blockSource:  c.opts.BlockSource,
+		pollInterval: c.pollInterval(),
+		metricPrefix: prefix,
+	}
+}
+
+var (
+	pollersMu sync.Mutex
+	// pollers holds the running poller of each node URL.
+	pollers = make(map[string]*blockPoller)
+)
+
+// pollerFor returns the poller of the node, which is idle when no client holds it.
+func pollerFor(url string) *blockPoller {
+	pollersMu.Lock()
+	defer pollersMu.Unlock()
+	if poller, ok := pollers[url]; ok {
+		return poller
+	}
+	return &blockPoller{url: url}
+}
+
+// acquirePoller adds the client to the poller of its node, starting the poller for the first client.
+// The client is removed once it is closed, at the latest when the test ends. It fails when the client sets the
+// blockSource, blockPollInterval or metricPrefix of the node differently than the poller runs with,
+// since the poller would ignore them.
+func (c *Client) acquirePoller() error {
+	pollersMu.Lock()
+	defer pollersMu.Unlock()
+
+	settings := c.pollerSettings()
+	poller, ok := pollers[c.opts.URL]
+	if ok && poller.settings != settings {
+		return fmt.Errorf(
+			"the block monitor of %s runs with blockSource %q, blockPollInterval %s and metricPrefix %q, "+
+				"every client of the node must use the same",
+			c.opts.URL, poller.settings.blockSource, poller.settings.pollInterval, poller.settings.metricPrefix,
+		)
+	}
+	if !ok {
+		poller = &blockPoller{url: c.opts.URL, settings: settings}
+		pollers[c.opts.URL] = poller
+	}
+
+	poller.mu.Lock()
+	poller.clients = append(poller.clients, c)
+	poller.mu.Unlock()
+
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		poller.cancel = cancel
+		go poller.poll(ctx)
+	}
+
+	context.AfterFunc(c.ctx, c.releasePoller)
+	return nil
+}
+
+// releasePoller removes the client from the poller of its node, stopping the poller with the last client.
+func (c *Client) releasePoller() {
+	pollersMu.Lock()
+	defer pollersMu.Unlock()
+
+	poller, ok := pollers[c.opts.URL]
+	if !ok {
+		return
+	}
+
+	poller.mu.Lock()
+	for i, client := range poller.clients {
+		if client == c {
+			poller.clients = append(poller.clients[:i], poller.clients[i+1:]...)
+			break
+		}
+	}
+	remaining := len(poller.clients)
+	poller.mu.Unlock()
+
+	if remaining == 0 {
+		poller.cancel()
+		delete(pollers, c.opts.URL)
+	}
+}
+
+// subscribers returns the clients holding the poller.
+func (p *blockPoller) subscribers() []*Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Client(nil), p.clients...)
+}
+
+// reporter returns the client the poller fetches blocks and pushes samples through: the first one
+// whose VU is running, since the samples of a VU still in its init phase are dropped, or nil when no
+// client holds the poller.
+func (p *blockPoller) reporter() *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range p.clients {
+		if c.vu != nil && c.vu.State() != nil {
+			return c
+		}
+	}
+	if len(p.clients) == 0 {
+		return nil
+	}
+	return p.clients[0]
+}
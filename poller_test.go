@@ -0,0 +1,86 @@
+package xk6_vechain
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/thorgo"
+	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/event"
+	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/js/modulestest"
+)
+
+// waitFor polls the condition until it holds, failing the test after a few seconds.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPollerOutlivesTheInitContext(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocks/0" {
+			// the genesis block, fetched once by the thor client
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		requests.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	opts, err := newOptionsFrom(map[string]interface{}{"url": server.URL, "blockPollInterval": "10ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thorClient, err := client.New(server.URL, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	events := event.NewEventSystem(10, logger)
+	initCtx, endInit := context.WithCancel(context.Background())
+	c := &Client{
+		thor:    thorgo.FromClient(thorClient),
+		vu:      &modulestest.VU{CtxField: initCtx, EventsField: common.Events{Global: events, Local: events}},
+		logger:  logger,
+		opts:    opts,
+		tracker: newTxTracker(statsFor(opts.URL), opts.TrackFinality),
+	}
+	if err := c.start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	endInit()
+	polled := requests.Load()
+	waitFor(t, "the poller to keep polling", func() bool { return requests.Load() >= polled+2 })
+	if !slices.Contains(pollerFor(opts.URL).subscribers(), c) {
+		t.Fatal("expected the client to still hold the poller once its init context is done")
+	}
+
+	wait := events.Emit(&event.Event{Type: event.TestEnd})
+	if err := wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if c.ctx.Err() == nil {
+		t.Fatal("expected the client to be closed once the test ends")
+	}
+	waitFor(t, "the poller to stop", func() bool { return len(pollerFor(opts.URL).subscribers()) == 0 })
+}
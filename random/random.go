@@ -3,14 +3,23 @@ package random
 import (
 	crand "crypto/rand"
 	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	mrand "math/rand"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
 // prng is a pseudo random number generator seeded by strong randomness.
 // The randomness is printed on startup in order to make failures reproducible.
-var prng = initRand()
+// It is not safe for concurrent use, so every access must hold prngMu.
+var (
+	prng   = initRand()
+	prngMu sync.Mutex
+)
 
 func initRand() *mrand.Rand {
 	var seed [8]byte
@@ -22,10 +31,50 @@ func initRand() *mrand.Rand {
 // Bytes generates a random byte slice with specified length.
 func Bytes(n int) []byte {
 	r := make([]byte, n)
+	prngMu.Lock()
 	prng.Read(r)
+	prngMu.Unlock()
 	return r
 }
 
+// Nonce generates a random, non-zero transaction nonce. It reads from crypto/rand rather than
+// the shared prng, so concurrent VUs cannot produce colliding nonces.
+func Nonce() uint64 {
+	var b [8]byte
+	for {
+		crand.Read(b[:])
+		if nonce := binary.BigEndian.Uint64(b[:]); nonce != 0 {
+			return nonce
+		}
+	}
+}
+
+// Hex generates a random 0x-prefixed hex string encoding n bytes.
+func Hex(n int) string {
+	return "0x" + hex.EncodeToString(Bytes(n))
+}
+
+// alphanumeric is the default charset used by String.
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// String generates a random string of length n. The characters are drawn from the
+// optional charset, or from the alphanumeric charset if none is provided.
+func String(n int, charset ...string) string {
+	chars := alphanumeric
+	if len(charset) > 0 && charset[0] != "" {
+		chars = charset[0]
+	}
+
+	runes := []rune(chars)
+	r := make([]rune, n)
+	prngMu.Lock()
+	defer prngMu.Unlock()
+	for i := range r {
+		r[i] = runes[prng.Intn(len(runes))]
+	}
+	return string(r)
+}
+
 // Hash generates a random hash.
 func Hash() common.Hash {
 	return common.BytesToHash(Bytes(common.HashLength))
@@ -38,10 +87,70 @@ func Address() common.Address {
 
 // Uint8 generates a random uint8.
 func Uint8() uint8 {
+	prngMu.Lock()
+	defer prngMu.Unlock()
 	return uint8(prng.Intn(256))
 }
 
+// Intn returns a random int in [0, n).
+func Intn(n int) int {
+	prngMu.Lock()
+	defer prngMu.Unlock()
+	return prng.Intn(n)
+}
+
 // Element returns a random element from the slice.
 func Element[T any](slice []T) T {
+	prngMu.Lock()
+	defer prngMu.Unlock()
 	return slice[prng.Intn(len(slice))]
 }
+
+// ErrNoCandidates is returned by ElementExcluding when every element is excluded.
+var ErrNoCandidates = errors.New("no element other than the excluded one")
+
+// ElementExcluding returns a random element from the slice that is not equal to exclude, or
+// ErrNoCandidates if the slice contains no such element.
+func ElementExcluding[T comparable](slice []T, exclude T) (T, error) {
+	candidates := make([]T, 0, len(slice))
+	for _, v := range slice {
+		if v != exclude {
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		var zero T
+		return zero, ErrNoCandidates
+	}
+	return Element(candidates), nil
+}
+
+// Random is the JS module exposing the random helpers.
+type Random struct{}
+
+// String generates a random string of length n, optionally from the given charset.
+func (*Random) String(n int, charset ...string) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("length must not be negative, got %d", n)
+	}
+	return String(n, charset...), nil
+}
+
+// Hex generates a random 0x-prefixed hex string encoding n bytes.
+func (*Random) Hex(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("length must not be negative, got %d", n)
+	}
+	return Hex(n), nil
+}
+
+// ElementExcluding returns a random element from the slice that is not equal to exclude.
+func (*Random) ElementExcluding(slice []string, exclude string) (string, error) {
+	return ElementExcluding(slice, exclude)
+}
+
+// Nonce generates a random transaction nonce. It is returned as a 0x-prefixed hex string,
+// since JS numbers cannot represent every uint64.
+func (*Random) Nonce() string {
+	return hexutil.EncodeUint64(Nonce())
+}
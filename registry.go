@@ -0,0 +1,211 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// reconcileWorkers is the number of receipts fetched concurrently while reconciling.
+	reconcileWorkers = 16
+	// maxRegisteredTxs bounds the registry, the oldest transactions are dropped beyond it.
+	maxRegisteredTxs = 1_000_000
+)
+
+// sentTx is a transaction recorded in the registry along with the effect it was sent for.
+type sentTx struct {
+	ID        common.Hash
+	Effect    string
+	Submitted time.Time
+}
+
+// txRegistry records every transaction sent to a node, across all VUs, for the end of test reconciliation.
+type txRegistry struct {
+	mu      sync.Mutex
+	txs     []sentTx
+	dropped int
+}
+
+// registries holds the txRegistry of each node URL.
+var registries sync.Map
+
+// registryFor returns the process-wide registry of the node.
+func registryFor(url string) *txRegistry {
+	registry, _ := registries.LoadOrStore(url, &txRegistry{})
+	return registry.(*txRegistry)
+}
+
+// record adds a sent transaction to the registry.
+func (r *txRegistry) record(id common.Hash, effect string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.txs) >= maxRegisteredTxs {
+		r.txs = r.txs[1:]
+		r.dropped++
+	}
+	r.txs = append(r.txs, sentTx{ID: id, Effect: effect, Submitted: time.Now()})
+}
+
+// size returns the number of recorded transactions.
+func (r *txRegistry) size() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.txs)
+}
+
+// snapshot returns a copy of the recorded transactions and the number of dropped ones.
+func (r *txRegistry) snapshot() ([]sentTx, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]sentTx(nil), r.txs...), r.dropped
+}
+
+// record adds a transaction sent by the client to the registry of its node.
+func (c *Client) record(id common.Hash, effect string) {
+	registryFor(c.opts.URL).record(id, effect)
+}
+
+// ReconciledTx is a registered transaction that did not have its intended effect.
+type ReconciledTx struct {
+	TxID    string `js:"txID"`
+	Effect  string `js:"effect"`
+	Outcome string `js:"outcome"`
+	// Clause is the index of the clause that reverted the transaction, or -1.
+	Clause int `js:"clause"`
+}
+
+// Reconciliation is the correctness verdict of every transaction sent to the node during the test.
+// Dropped counts the transactions that were evicted from the registry and could not be checked.
+type Reconciliation struct {
+	OK        bool           `js:"ok"`
+	Total     int            `js:"total"`
+	Succeeded int            `js:"succeeded"`
+	Reverted  int            `js:"reverted"`
+	Missing   int            `js:"missing"`
+	Dropped   int            `js:"dropped"`
+	Failures  []ReconciledTx `js:"failures"`
+	Summary   string         `js:"summary"`
+}
+
+// ReconcileTransactions checks the receipt of every transaction sent to the node by any VU.
+// A transaction passes when it is included and did not revert. Each outcome is counted in
+// vechain_reconciled_txs. It is intended to be called from teardown once the chain has settled.
+func (c *Client) ReconcileTransactions() (*Reconciliation, error) {
+	txs, dropped := registryFor(c.opts.URL).snapshot()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		work     = make(chan sentTx)
+		result   = &Reconciliation{Total: len(txs), Dropped: dropped}
+	)
+
+	for i := 0; i < reconcileWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tx := range work {
+				outcome, clause, err := c.reconcile(tx)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					if firstErr == nil {
+						firstErr = err
+					}
+				case outcome == "success":
+					result.Succeeded++
+				default:
+					if outcome == "reverted" {
+						result.Reverted++
+					} else {
+						result.Missing++
+					}
+					result.Failures = append(result.Failures, ReconciledTx{
+						TxID:    tx.ID.Hex(),
+						Effect:  tx.Effect,
+						Outcome: outcome,
+						Clause:  clause,
+					})
+				}
+				mu.Unlock()
+
+				if err == nil {
+					tags := map[string]string{"outcome": outcome}
+					if clause >= 0 {
+						tags["clause"] = strconv.Itoa(clause)
+					}
+					c.pushSample(c.metrics.ReconciledTxs, 1, tags)
+				}
+			}
+		}()
+	}
+
+	for _, tx := range txs {
+		work <- tx
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	result.OK = result.Succeeded == result.Total
+	result.Summary = result.summary()
+	return result, nil
+}
+
+// reconcile returns the outcome of a registered transaction: success, reverted or missing, along
+// with the index of the clause that reverted it, or -1.
+func (c *Client) reconcile(tx sentTx) (string, int, error) {
+	receipt, err := c.thor.Client.TransactionReceipt(tx.ID)
+	if errors.Is(err, client.ErrNotFound) {
+		return "missing", -1, nil
+	}
+	if err != nil {
+		return "", -1, fmt.Errorf("failed to fetch receipt of %s: %w", tx.ID.Hex(), err)
+	}
+	if receipt.Reverted {
+		_, clause, err := c.clauseStatuses(tx.ID, receipt)
+		if err != nil {
+			return "", -1, err
+		}
+		return "reverted", clause, nil
+	}
+	return "success", -1, nil
+}
+
+// summary renders the reconciliation as a text section for the end of test summary.
+func (r *Reconciliation) summary() string {
+	var b strings.Builder
+	verdict := "PASS"
+	if !r.OK {
+		verdict = "FAIL"
+	}
+
+	fmt.Fprintf(&b, "transaction reconciliation: %s\n", verdict)
+	fmt.Fprintf(&b, "  total......: %d\n", r.Total)
+	fmt.Fprintf(&b, "  succeeded..: %d\n", r.Succeeded)
+	fmt.Fprintf(&b, "  reverted...: %d\n", r.Reverted)
+	fmt.Fprintf(&b, "  missing....: %d\n", r.Missing)
+	if r.Dropped > 0 {
+		fmt.Fprintf(&b, "  dropped....: %d (not checked)\n", r.Dropped)
+	}
+	for _, tx := range r.Failures {
+		if tx.Clause >= 0 {
+			fmt.Fprintf(&b, "  - %s at clause %d %s (%s)\n", tx.Outcome, tx.Clause, tx.TxID, tx.Effect)
+			continue
+		}
+		fmt.Fprintf(&b, "  - %s %s (%s)\n", tx.Outcome, tx.TxID, tx.Effect)
+	}
+	return b.String()
+}
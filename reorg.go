@@ -0,0 +1,101 @@
+package xk6_vechain
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// maxReorgDepth is how many blocks of the observed chain are kept to measure the depth of a reorg.
+const maxReorgDepth = 100
+
+// headHistory is the chain of heads observed by the block monitor of a node, by block number.
+type headHistory struct {
+	mu     sync.Mutex
+	ids    map[uint64]common.Hash
+	latest uint64
+}
+
+// headHistories holds the headHistory of each node URL.
+var headHistories sync.Map
+
+// headHistoryFor returns the observed chain of the node, shared by every client of the node.
+func headHistoryFor(url string) *headHistory {
+	history, _ := headHistories.LoadOrStore(url, &headHistory{ids: make(map[uint64]common.Hash)})
+	return history.(*headHistory)
+}
+
+// observe records the block as the head of the chain and returns the number of previously observed
+// blocks it orphans, 0 when it extends the observed chain, along with the number of the last block
+// both chains share. The parents of the block are fetched with byID until the observed chain is met,
+// which also fills the blocks the monitor skipped.
+func (h *headHistory) observe(block *client.Block, byID func(common.Hash) (*client.Block, error)) (int, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.ids) == 0 {
+		h.ids[block.Number] = block.ID
+		h.latest = block.Number
+		return 0, block.Number
+	}
+
+	depth := 0
+	fork := block.Number
+	for number := range h.ids {
+		if number > block.Number {
+			delete(h.ids, number)
+			depth++
+		}
+	}
+	if id, ok := h.ids[block.Number]; ok && id != block.ID {
+		depth++
+		fork = block.Number - 1
+	}
+
+	for current, steps := block, 0; current.Number > 0 && steps < maxReorgDepth; steps++ {
+		id, ok := h.ids[current.Number-1]
+		if ok && id == current.ParentID {
+			break
+		}
+		if !ok && current.Number-1 <= h.latest {
+			// the history does not go back that far
+			break
+		}
+		parent, err := byID(current.ParentID)
+		if err != nil {
+			break
+		}
+		if ok {
+			depth++
+			fork = parent.Number - 1
+		}
+		h.ids[parent.Number] = parent.ID
+		current = parent
+	}
+
+	h.ids[block.Number] = block.ID
+	h.latest = block.Number
+	for number := range h.ids {
+		if number+maxReorgDepth < block.Number {
+			delete(h.ids, number)
+		}
+	}
+	return depth, fork
+}
+
+// detectReorg records the new head of the node and counts a reorg in vechain_reorg, tagged with the
+// number of blocks orphaned, when the head switched to a block that does not extend the previous head.
+// It returns whether it did, and the number of the last block the old and the new chain share.
+func (c *Client) detectReorg(block *client.Block) (bool, uint64) {
+	depth, fork := headHistoryFor(c.opts.URL).observe(block, c.thor.Blocks.ByID)
+	if depth == 0 {
+		return false, fork
+	}
+
+	c.log().WithFields(logrus.Fields{"url": c.opts.URL, "head": block.Number, "depth": depth}).Warn("chain reorganized")
+	c.pushSample(c.metrics.Reorgs, 1, map[string]string{"depth": strconv.Itoa(depth)})
+	return true, fork
+}
@@ -0,0 +1,268 @@
+package xk6_vechain
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// recordedOp is a single submission captured in a recording, one JSON object per line.
+type recordedOp struct {
+	// Offset is the time since the recording started, in nanoseconds.
+	Offset int64 `json:"offset"`
+	// Signer is the account index of the sender, or -1 when the sender is not a managed account.
+	Signer int    `json:"signer"`
+	Effect string `json:"effect"`
+	// Raw is the signed transaction exactly as submitted, carrying the clauses, nonce, and gas.
+	Raw string `json:"raw"`
+}
+
+// recorder appends the submissions of every VU to a recording file.
+type recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	started time.Time
+}
+
+// recorders holds the recorder of each recording path.
+var recorders sync.Map
+
+// recorderFor returns the process-wide recorder writing to the path, truncating the file on first use.
+func recorderFor(path string) (*recorder, error) {
+	if r, ok := recorders.Load(path); ok {
+		return r.(*recorder), nil
+	}
+
+	// hold the lock until the file is open, so that concurrent writers wait for it
+	r := &recorder{}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actual, loaded := recorders.LoadOrStore(path, r)
+	if loaded {
+		return actual.(*recorder), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		recorders.Delete(path)
+		return nil, fmt.Errorf("failed to open recording %s: %w", path, err)
+	}
+	r.file = file
+	r.encoder = json.NewEncoder(file)
+	r.started = time.Now()
+	return r, nil
+}
+
+// write appends the submission, made at the given time, to the recording.
+func (r *recorder) write(raw string, effect string, signer int, submitted time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.encoder == nil {
+		return errors.New("recording is not open")
+	}
+	return r.encoder.Encode(recordedOp{
+		Offset: max(submitted.Sub(r.started), 0).Nanoseconds(),
+		Signer: signer,
+		Effect: effect,
+		Raw:    raw,
+	})
+}
+
+// close flushes the recording to disk and closes it. Later writes fail.
+func (r *recorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := errors.Join(r.file.Sync(), r.file.Close())
+	r.file, r.encoder = nil, nil
+	return err
+}
+
+// recording returns the recorder of the record option, or nil when it is not set.
+func (c *Client) recording() (*recorder, error) {
+	if c.opts.Record == "" {
+		return nil, nil
+	}
+	return recorderFor(c.opts.Record)
+}
+
+// recordOp appends the submission to the recording, once the node accepted it.
+func (r *recorder) recordOp(raw string, effect string, signer int, submitted time.Time) error {
+	if r == nil {
+		return nil
+	}
+	return r.write(raw, effect, signer, submitted)
+}
+
+// closeRecording closes the recording of the record option, so that it is complete on disk before the
+// process exits.
+func (c *Client) closeRecording() {
+	if c.opts.Record == "" {
+		return
+	}
+	if r, ok := recorders.Load(c.opts.Record); ok {
+		if err := r.(*recorder).close(); err != nil {
+			c.log().WithField("path", c.opts.Record).WithError(err).Warn("failed to close the recording")
+		}
+	}
+}
+
+// replayOptions configures replay.
+type replayOptions struct {
+	// Exact submits the recorded transactions unchanged. It requires the node to share the chain tag
+	// and the block references of the recording, otherwise the transactions are rebuilt and re-signed
+	// against the best block of the node, keeping their clauses, nonce, gas, and signer.
+	Exact bool `json:"exact,omitempty"`
+	// Speed scales the recorded timing, e.g. 2 replays twice as fast. Defaults to 1.
+	Speed float64 `json:"speed,omitempty"`
+}
+
+// ReplayResult is the outcome of a replay.
+type ReplayResult struct {
+	Operations int    `js:"operations"`
+	Sent       uint64 `js:"sent"`
+	Failed     uint64 `js:"failed"`
+}
+
+// Replay submits the operations of a recording made with the record option, preserving their order and
+// timing, blocking until every operation is submitted. The client must use the mnemonic and number of
+// accounts of the recording, so that every signer is available.
+func (c *Client) Replay(path string, options map[string]interface{}) (*ReplayResult, error) {
+	var opts replayOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+
+	ops, err := readRecording(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg     sync.WaitGroup
+		sent   atomic.Uint64
+		failed atomic.Uint64
+		ctx    = c.vu.Context()
+	)
+
+	started := time.Now()
+	for _, op := range ops {
+		at := started.Add(time.Duration(float64(op.Offset) / opts.Speed))
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		case <-time.After(time.Until(at)):
+		}
+
+		wg.Add(1)
+		go func(op recordedOp) {
+			defer wg.Done()
+			if err := c.replayOp(op, opts.Exact); err != nil {
+				failed.Add(1)
+				return
+			}
+			sent.Add(1)
+		}(op)
+	}
+	wg.Wait()
+
+	return &ReplayResult{
+		Operations: len(ops),
+		Sent:       sent.Load(),
+		Failed:     failed.Load(),
+	}, nil
+}
+
+// replayOp submits a recorded operation, rebuilding and re-signing it unless exact is set.
+func (c *Client) replayOp(op recordedOp, exact bool) error {
+	raw := op.Raw
+	if !exact {
+		rebuilt, err := c.rebuild(op)
+		if err != nil {
+			return err
+		}
+		raw = rebuilt
+	}
+
+	_, err := c.sendRaw(raw, "replay "+op.Effect, op.Signer)
+	return err
+}
+
+// rebuild re-signs the recorded transaction against the chain tag and best block of the node.
+func (c *Client) rebuild(op recordedOp) (string, error) {
+	manager, err := c.signer(op.Signer)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := hexutil.Decode(op.Raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid recorded transaction: %w", err)
+	}
+	recorded, err := transaction.Decode(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid recorded transaction: %w", err)
+	}
+
+	tx, err := c.thor.Transactor(recorded.Clauses(), manager.Address()).
+		Nonce(recorded.Nonce()).
+		Gas(recorded.Gas()).
+		GasPriceCoef(recorded.GasPriceCoef()).
+		Expiration(recorded.Expiration()).
+		DependsOn(recorded.DependsOn()).
+		Build()
+	if err != nil {
+		return "", err
+	}
+
+	defer c.beginSigning(op.Signer)()
+	signature, err := manager.SignTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	return tx.WithSignature(signature).Encoded()
+}
+
+// readRecording reads the operations of a recording in order.
+func readRecording(path string) ([]recordedOp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording %s: %w", path, err)
+	}
+	defer file.Close()
+
+	ops := make([]recordedOp, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var op recordedOp
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			return nil, fmt.Errorf("invalid recording %s at line %d: %w", path, line, err)
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", path, err)
+	}
+	return ops, nil
+}
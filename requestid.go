@@ -0,0 +1,103 @@
+package xk6_vechain
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// requestIDHeader is the header the ID of every request to the node is sent in, with requestIds.
+	requestIDHeader = "X-Request-Id"
+	// maxRecentRequests is how many requests recentRequests returns.
+	maxRecentRequests = 100
+)
+
+// NodeRequest is a request made to the node, identified by the ID sent in its X-Request-Id header.
+// Status is 0 when the request failed without a response, and Duration is in ms.
+type NodeRequest struct {
+	ID       string  `js:"id"`
+	Call     string  `js:"call"`
+	Status   int     `js:"status"`
+	Duration float64 `js:"duration"`
+	Time     int64   `js:"time"` // unix ms
+}
+
+// requestLog holds the latest requests made by a client.
+type requestLog struct {
+	mu       sync.Mutex
+	requests []NodeRequest
+}
+
+// add records the request, dropping the oldest once maxRecentRequests are recorded.
+func (l *requestLog) add(request NodeRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.requests) >= maxRecentRequests {
+		l.requests = l.requests[1:]
+	}
+	l.requests = append(l.requests, request)
+}
+
+// recordRequest records a request made to the node with its ID.
+func (l *requestLog) recordRequest(id, call string, started time.Time, status int) {
+	l.add(NodeRequest{
+		ID:       id,
+		Call:     call,
+		Status:   status,
+		Duration: metrics.D(time.Since(started)),
+		Time:     started.UnixMilli(),
+	})
+}
+
+// requestError is a failed request to the node, with the ID sent in its X-Request-Id header. The
+// request failed without a response, or the node answered it with an error.
+type requestError struct {
+	id  string
+	err error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// newRequestID returns a random request ID, as 32 hex characters.
+func newRequestID() string {
+	var id [16]byte
+	_, _ = crand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// RecentRequests returns the latest requests the client made to the node, oldest first, with the ID
+// sent in their X-Request-Id header, so that a failure can be matched with the access logs of the node.
+// It is empty unless the requestIds option is set.
+func (c *Client) RecentRequests() []NodeRequest {
+	if c.requests == nil {
+		return []NodeRequest{}
+	}
+	c.requests.mu.Lock()
+	defer c.requests.mu.Unlock()
+	return append([]NodeRequest{}, c.requests.requests...)
+}
+
+// LastRequestID returns the ID of the latest request the client made to the node, or an empty string.
+// The requests of the background goroutines, e.g. the block poller, count as well, so the ID of a
+// failed request is best read from the requestId of the error thrown for it.
+func (c *Client) LastRequestID() string {
+	if c.requests == nil {
+		return ""
+	}
+	c.requests.mu.Lock()
+	defer c.requests.mu.Unlock()
+	if len(c.requests.requests) == 0 {
+		return ""
+	}
+	return c.requests.requests[len(c.requests.requests)-1].ID
+}
@@ -0,0 +1,74 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/xk6-vechain/random"
+)
+
+const (
+	// routingRoundRobin sends each submission to the next node, in turn across all VUs.
+	routingRoundRobin = "roundRobin"
+	// routingRandom sends each submission to a random node.
+	routingRandom = "random"
+	// routingSticky sends every submission of a VU to the same node.
+	routingSticky = "sticky"
+)
+
+// routeCounters holds the round-robin counter of each node list, keyed by the primary URL.
+var routeCounters sync.Map
+
+// routeCounterFor returns the round-robin counter shared by all VUs of the node list.
+func routeCounterFor(url string) *atomic.Uint64 {
+	counter, _ := routeCounters.LoadOrStore(url, new(atomic.Uint64))
+	return counter.(*atomic.Uint64)
+}
+
+// submissionNode is a node transactions can be submitted to.
+type submissionNode struct {
+	url    string
+	client *client.Client
+}
+
+// newSubmissionNodes connects to every node of the list, checking that they all follow the chain of the
+// primary node.
+func newSubmissionNodes(urls []string, chainTag byte, newClient func(url string) (*client.Client, error)) ([]submissionNode, error) {
+	nodes := make([]submissionNode, len(urls))
+	for i, url := range urls {
+		thorClient, err := newClient(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+		}
+		if tag := thorClient.ChainTag(); tag != chainTag {
+			return nil, fmt.Errorf("node %s is on chain %#x, not %#x", url, tag, chainTag)
+		}
+		nodes[i] = submissionNode{url: url, client: thorClient}
+	}
+	return nodes, nil
+}
+
+// route returns the node the next submission is sent to, according to the routing option.
+// Without urls, every submission goes to the node of the url option.
+func (c *Client) route() *client.Client {
+	if len(c.nodes) == 0 {
+		return c.thor.Client
+	}
+
+	var index int
+	switch c.opts.Routing {
+	case routingRandom:
+		index = random.Intn(len(c.nodes))
+	case routingSticky:
+		var vu uint64
+		if state := c.vu.State(); state != nil {
+			vu = state.VUID
+		}
+		index = int(vu % uint64(len(c.nodes)))
+	default:
+		index = int((routeCounterFor(c.opts.URL).Add(1) - 1) % uint64(len(c.nodes)))
+	}
+	return c.nodes[index].client
+}
@@ -0,0 +1,74 @@
+package xk6_vechain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/darrenvechain/thor-go-sdk/thorgo"
+	"go.k6.io/k6/js/modulestest"
+	"go.k6.io/k6/lib"
+)
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   int
+		routing string
+		vu      *lib.State
+		// routes are the indexes of the nodes of the successive submissions, -1 for the node of url
+		routes []int
+	}{
+		{name: "without urls", nodes: 0, routing: routingRoundRobin, routes: []int{-1, -1}},
+		{name: "round robin", nodes: 3, routing: routingRoundRobin, routes: []int{0, 1, 2, 0}},
+		{name: "sticky", nodes: 3, routing: routingSticky, vu: &lib.State{VUID: 5}, routes: []int{2, 2, 2}},
+		{name: "sticky in the init context", nodes: 3, routing: routingSticky, routes: []int{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &client.Client{}
+			c := &Client{
+				thor: thorgo.FromClient(primary),
+				vu:   &modulestest.VU{StateField: tt.vu},
+				opts: &options{URL: "http://" + t.Name(), Routing: tt.routing},
+			}
+			for i := 0; i < tt.nodes; i++ {
+				c.nodes = append(c.nodes, submissionNode{client: &client.Client{}})
+			}
+
+			routes := make([]int, 0, len(tt.routes))
+			for range tt.routes {
+				route := c.route()
+				index := -1
+				for i, node := range c.nodes {
+					if node.client == route {
+						index = i
+					}
+				}
+				if index == -1 && route != primary {
+					t.Fatal("expected the submission to be routed to a node")
+				}
+				routes = append(routes, index)
+			}
+			if !reflect.DeepEqual(routes, tt.routes) {
+				t.Fatalf("expected the routes %v, got %v", tt.routes, routes)
+			}
+		})
+	}
+}
+
+func TestRouteRandom(t *testing.T) {
+	c := &Client{opts: &options{Routing: routingRandom}}
+	for i := 0; i < 3; i++ {
+		c.nodes = append(c.nodes, submissionNode{client: &client.Client{}})
+	}
+
+	seen := make(map[*client.Client]bool)
+	for i := 0; i < 1000 && len(seen) < len(c.nodes); i++ {
+		seen[c.route()] = true
+	}
+	if len(seen) != len(c.nodes) {
+		t.Fatalf("expected the submissions to be spread over the %d nodes, got %d", len(c.nodes), len(seen))
+	}
+}
@@ -0,0 +1,343 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/thor-go-sdk/thorgo/transactions"
+	"github.com/darrenvechain/xk6-vechain/random"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// mempoolPollInterval is how often a submitted transaction is looked up in the mempool.
+	mempoolPollInterval = 100 * time.Millisecond
+	// mempoolTimeout is how long a submitted transaction is looked up before giving up.
+	mempoolTimeout = 30 * time.Second
+	// maxMempoolTrackers is how many transactions are looked up in the mempool at once.
+	maxMempoolTrackers = 256
+)
+
+// sendRaw posts the hex encoded transaction to the node and returns its ID.
+// The transaction is recorded in the registry along with its intended effect, and appended to the
+// recording when the record option is set.
+// The signer is the account index of the sender, or -1 when the sender is not a managed account.
+// Submissions from the same account are serialized with the sequenceAccounts option.
+func (c *Client) sendRaw(raw string, effect string, signer int) (common.Hash, error) {
+	if !strings.HasPrefix(raw, "0x") {
+		raw = "0x" + raw
+	}
+
+	if statsFor(c.opts.URL).draining.Load() {
+		return common.Hash{}, errDraining
+	}
+
+	sequence, endSequence := c.sequence(signer)
+	defer endSequence()
+	if sequence != nil {
+		if id, ok := txIDOf(raw); ok && sequence.submitted(id) {
+			return common.Hash{}, fmt.Errorf("%w: %s", errDuplicateTx, id.Hex())
+		}
+	}
+
+	recording, err := c.recording()
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	submitted := time.Now()
+	res, err := c.route().SendRawTransaction(raw)
+	if err != nil {
+		return common.Hash{}, c.fail(err)
+	}
+	sequence.add(res.ID)
+	if err := recording.recordOp(raw, effect, signer, submitted); err != nil {
+		// the transaction is already submitted, so the submission still succeeds
+		c.log().WithError(err).Warn("failed to record the transaction")
+	}
+
+	c.tracker.add(res.ID, submitted, signer, gasOf(raw))
+	c.record(res.ID, effect)
+
+	if c.opts.TrackMempool {
+		select {
+		case mempoolTrackers <- struct{}{}:
+			go c.trackMempoolAcceptance(res.ID, submitted, signer)
+		default:
+		}
+	}
+
+	return res.ID, nil
+}
+
+// sendInFlight sends the transaction with sendRaw once the signer has an in-flight slot, which is held
+// until the transaction is mined, and freed right away when the submission fails.
+func (c *Client) sendInFlight(raw string, effect string, signer int) (common.Hash, error) {
+	slot, err := c.acquireInFlight(signer)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	id, err := c.sendRaw(raw, effect, signer)
+	if err != nil {
+		c.releaseInFlight(slot)
+		return common.Hash{}, err
+	}
+	if slot != nil && !c.tracker.hold(id, slot) {
+		// already mined
+		c.releaseInFlight(slot)
+	}
+	return id, nil
+}
+
+// mempoolTrackers bounds the transactions looked up in the mempool at once, across all clients. The
+// transactions submitted while it is reached are not tracked.
+var mempoolTrackers = make(chan struct{}, maxMempoolTrackers)
+
+// trackMempoolAcceptance polls the node until the transaction is visible as pending and records
+// the time since submission, separating admission latency from block inclusion latency. It gives up
+// after mempoolTimeout or once the client is closed.
+func (c *Client) trackMempoolAcceptance(id common.Hash, submitted time.Time, signer int) {
+	defer func() { <-mempoolTrackers }()
+
+	ticker := time.NewTicker(mempoolPollInterval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(time.Until(submitted.Add(mempoolTimeout)))
+	defer deadline.Stop()
+
+	for {
+		if _, err := c.thor.Client.PendingTransaction(id); err == nil {
+			c.pushSample(c.metrics.MempoolAcceptTime, metrics.D(time.Since(submitted)), c.signerTags(signer, nil))
+			return
+		}
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Send submits the hex encoded transaction and returns its ID without waiting for it to be mined. Only
+// the submission is timed, in vechain_submit_duration. Its inclusion is resolved in the background by
+// the block monitor, which records vechain_time_to_mine tagged with the background mode once the
+// transaction is seen in a block, and vechain_tx_not_mined tagged evicted when it never is.
+func (c *Client) Send(raw string) (string, error) {
+	tx, err := decodeRaw(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+	origin, err := tx.Origin()
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+	signer := c.signerIndex(origin)
+
+	started := time.Now()
+	id, err := c.sendInFlight(raw, "send", signer)
+	if err != nil {
+		return "", err
+	}
+	c.pushSample(c.metrics.SubmitDuration, metrics.D(time.Since(started)), c.signerTags(signer, nil))
+	c.tracker.resolveInBackground(id)
+
+	return id.Hex(), nil
+}
+
+// SendRawTransaction posts the hex encoded RLP transaction to the node as is and returns its ID, so that
+// transactions signed offline or in setup can be replayed without signing in the iterations. The
+// transaction must still be valid when it is posted: its blockRef and expiration are not touched.
+func (c *Client) SendRawTransaction(raw string) (string, error) {
+	tx, err := decodeRaw(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+	origin, err := tx.Origin()
+	if err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+	signer := c.signerIndex(origin)
+
+	id, err := c.sendInFlight(raw, "raw transaction from "+origin.Hex(), signer)
+	if err != nil {
+		return "", err
+	}
+	return id.Hex(), nil
+}
+
+// SendToolchainTransaction builds, signs, and sends a toolchain transaction, returning its ID.
+// The options override the gas, gasPriceCoef, expiration, blockRef and dependsOn of the transaction.
+func (c *Client) SendToolchainTransaction(address string, options map[string]interface{}) (string, error) {
+	signer, err := c.randomSigner()
+	if err != nil {
+		return "", err
+	}
+	return c.SendToolchainTransactionFrom(address, signer, options)
+}
+
+// SendToolchainTransactionFrom builds, signs, and sends a toolchain transaction from the account at the
+// signer index, returning its ID.
+func (c *Client) SendToolchainTransactionFrom(address string, signer int, options map[string]interface{}) (string, error) {
+	raw, err := c.NewToolchainTransactionFrom(address, signer, options)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := c.sendInFlight(raw, "toolchain call to "+address, signer)
+	if err != nil {
+		return "", err
+	}
+	return id.Hex(), nil
+}
+
+// gasOf returns the gas limit of the hex encoded transaction, or 0 when it cannot be decoded.
+func gasOf(raw string) uint64 {
+	tx, err := decodeRaw(raw)
+	if err != nil {
+		return 0
+	}
+	return tx.Gas()
+}
+
+// txIDOf returns the ID of the hex encoded transaction, if it can be decoded.
+func txIDOf(raw string) (common.Hash, bool) {
+	tx, err := decodeRaw(raw)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	id := tx.ID()
+	return id, id != common.Hash{}
+}
+
+// decodeRaw decodes the hex encoded transaction, with or without the 0x prefix.
+func decodeRaw(raw string) (*transaction.Transaction, error) {
+	if !strings.HasPrefix(raw, "0x") {
+		raw = "0x" + raw
+	}
+	encoded, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return transaction.Decode(encoded)
+}
+
+// txParams overrides the defaults of the transactions built by the client. Zero values keep the defaults.
+type txParams struct {
+	// Gas is the gas limit, estimated by the node when 0.
+	Gas uint64 `json:"gas,omitempty"`
+	// GasPriceCoef is the gas price coefficient, from 0 to 255.
+	GasPriceCoef uint8 `json:"gasPriceCoef,omitempty"`
+	// Expiration is the number of blocks after the block reference the transaction expires, 30 when 0.
+	Expiration uint32 `json:"expiration,omitempty"`
+	// BlockRef is the block reference, either a block number or 8 bytes of hex. The best block when empty.
+	BlockRef string `json:"blockRef,omitempty"`
+	// DependsOn is the ID of a transaction that must be executed successfully before this one.
+	DependsOn string `json:"dependsOn,omitempty"`
+}
+
+// parseTxParams decodes the transaction parameters from the options of a send call.
+func parseTxParams(options map[string]interface{}) (txParams, error) {
+	var params txParams
+	if err := decodeOptions(options, &params); err != nil {
+		return txParams{}, err
+	}
+	return params, nil
+}
+
+// apply sets the overridden parameters on the transactor.
+func (p txParams) apply(transactor *transactions.Transactor) (*transactions.Transactor, error) {
+	if p.Gas > 0 {
+		transactor = transactor.Gas(p.Gas)
+	}
+	if p.GasPriceCoef > 0 {
+		transactor = transactor.GasPriceCoef(p.GasPriceCoef)
+	}
+	if p.Expiration > 0 {
+		transactor = transactor.Expiration(p.Expiration)
+	}
+	if p.BlockRef != "" {
+		blockRef, err := parseBlockRef(p.BlockRef)
+		if err != nil {
+			return nil, err
+		}
+		transactor = transactor.BlockRef(blockRef)
+	}
+	if p.DependsOn != "" {
+		id, err := parseTxID(p.DependsOn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependsOn: %w", err)
+		}
+		transactor = transactor.DependsOn(&id)
+	}
+	return transactor, nil
+}
+
+// parseBlockRef parses a block reference given as a block number or as 8 bytes of hex.
+func parseBlockRef(blockRef string) (transaction.BlockRef, error) {
+	if !strings.HasPrefix(blockRef, "0x") {
+		number, err := strconv.ParseUint(blockRef, 10, 32)
+		if err != nil {
+			return transaction.BlockRef{}, fmt.Errorf("invalid blockRef %q", blockRef)
+		}
+		return transaction.NewBlockRef(uint32(number)), nil
+	}
+
+	decoded, err := hexutil.Decode(blockRef)
+	if err != nil || len(decoded) != 8 {
+		return transaction.BlockRef{}, fmt.Errorf("invalid blockRef %q, expected 8 bytes of hex", blockRef)
+	}
+	return transaction.BlockRef(decoded), nil
+}
+
+// signClauses builds a transaction of the clauses with the params and signs it with the account at the
+// signer index, returning it hex encoded.
+func (c *Client) signClauses(clauses []*transaction.Clause, signer int, params txParams) (string, error) {
+	manager, err := c.signer(signer)
+	if err != nil {
+		return "", err
+	}
+
+	transactor, err := params.apply(c.thor.Transactor(clauses, manager.Address()).Nonce(random.Nonce()))
+	if err != nil {
+		return "", err
+	}
+	tx, err := transactor.Build()
+	if err != nil {
+		return "", err
+	}
+
+	endSigning := c.beginSigning(signer)
+	signature, err := manager.SignTransaction(tx)
+	endSigning()
+	if err != nil {
+		return "", err
+	}
+
+	return tx.WithSignature(signature).Encoded()
+}
+
+// sendClauses builds a transaction of the clauses with the params, signs it with the account at the
+// signer index, and sends it, returning its ID.
+func (c *Client) sendClauses(clauses []*transaction.Clause, signer int, params txParams, effect string) (common.Hash, error) {
+	raw, err := c.signClauses(clauses, signer, params)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return c.sendInFlight(raw, effect, signer)
+}
+
+// parseTxID parses a hex transaction ID.
+func parseTxID(id string) (common.Hash, error) {
+	decoded, err := hexutil.Decode(id)
+	if err != nil || len(decoded) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid transaction ID %q", id)
+	}
+	return common.BytesToHash(decoded), nil
+}
@@ -0,0 +1,83 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseBlockRef(t *testing.T) {
+	tests := []struct {
+		blockRef string
+		expected transaction.BlockRef
+		err      string
+	}{
+		{blockRef: "0", expected: transaction.NewBlockRef(0)},
+		{blockRef: "1234", expected: transaction.NewBlockRef(1234)},
+		{blockRef: "4294967295", expected: transaction.NewBlockRef(4294967295)},
+		{blockRef: "0x00000001000000ff", expected: transaction.BlockRef{0, 0, 0, 1, 0, 0, 0, 0xff}},
+		{blockRef: "4294967296", err: `invalid blockRef "4294967296"`},
+		{blockRef: "-1", err: `invalid blockRef "-1"`},
+		{blockRef: "latest", err: `invalid blockRef "latest"`},
+		{blockRef: "0x01", err: `invalid blockRef "0x01", expected 8 bytes of hex`},
+		{blockRef: "0x0000000100000000ff", err: `invalid blockRef "0x0000000100000000ff", expected 8 bytes of hex`},
+		{blockRef: "0xzz", err: `invalid blockRef "0xzz", expected 8 bytes of hex`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.blockRef, func(t *testing.T) {
+			blockRef, err := parseBlockRef(tt.blockRef)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected the error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if blockRef != tt.expected {
+				t.Fatalf("expected %x, got %x", tt.expected, blockRef)
+			}
+		})
+	}
+}
+
+func TestParseTxID(t *testing.T) {
+	id := "0x" + strings.Repeat("ab", common.HashLength)
+
+	tests := []struct {
+		name     string
+		id       string
+		expected common.Hash
+		err      string
+	}{
+		{name: "valid", id: id, expected: common.HexToHash(id)},
+		{name: "without the 0x prefix", id: id[2:], err: fmt.Sprintf("invalid transaction ID %q", id[2:])},
+		{name: "too short", id: id[:len(id)-2], err: fmt.Sprintf("invalid transaction ID %q", id[:len(id)-2])},
+		{name: "too long", id: id + "ab", err: fmt.Sprintf("invalid transaction ID %q", id+"ab")},
+		{name: "invalid hex", id: "0x" + strings.Repeat("zz", common.HashLength), err: "invalid transaction ID"},
+		{name: "empty", id: "", err: `invalid transaction ID ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseTxID(tt.id)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected an error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed != tt.expected {
+				t.Fatalf("expected %s, got %s", tt.expected, parsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// sequencedTxs is how many of the latest transactions of each account are remembered to reject duplicates.
+const sequencedTxs = 1024
+
+// errDuplicateTx is returned when a transaction is submitted again while its account is sequenced.
+var errDuplicateTx = errors.New("transaction already submitted")
+
+// accountSequence serializes the submissions of an account and remembers its latest transactions.
+type accountSequence struct {
+	mu     sync.Mutex
+	recent []common.Hash // oldest first
+	seen   map[common.Hash]struct{}
+}
+
+// sequencers holds the account sequences of each node URL, shared by all VUs.
+var sequencers sync.Map // url -> *sync.Map of common.Address -> *accountSequence
+
+// sequenceFor returns the process-wide sequence of the account on the node.
+func sequenceFor(url string, account common.Address) *accountSequence {
+	accounts, _ := sequencers.LoadOrStore(url, &sync.Map{})
+	sequence, _ := accounts.(*sync.Map).LoadOrStore(account, &accountSequence{seen: make(map[common.Hash]struct{})})
+	return sequence.(*accountSequence)
+}
+
+// sequence waits for the submissions of the account at the signer index that are in progress, and
+// returns the sequence of the account along with the function ending this submission. With the sequenceAccounts option, submissions from an account
+// are serialized, while different accounts still submit in parallel. It is a no-op otherwise.
+func (c *Client) sequence(signer int) (*accountSequence, func()) {
+	if !c.opts.SequenceAccounts || signer < 0 || signer >= len(c.managers) {
+		return nil, func() {}
+	}
+	sequence := sequenceFor(c.opts.URL, c.managers[signer].Address())
+	sequence.mu.Lock()
+	return sequence, sequence.mu.Unlock
+}
+
+// submitted reports whether the transaction was already submitted from the account.
+// It is called with the sequence held.
+func (s *accountSequence) submitted(id common.Hash) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.seen[id]
+	return ok
+}
+
+// add remembers a transaction submitted from the account. It is called with the sequence held.
+func (s *accountSequence) add(id common.Hash) {
+	if s == nil {
+		return
+	}
+	if len(s.recent) >= sequencedTxs {
+		delete(s.seen, s.recent[0])
+		s.recent = s.recent[1:]
+	}
+	s.recent = append(s.recent, id)
+	s.seen[id] = struct{}{}
+}
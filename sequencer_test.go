@@ -0,0 +1,95 @@
+package xk6_vechain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestAccountSequence(t *testing.T) {
+	id := func(i int) common.Hash { return common.BigToHash(big.NewInt(int64(i + 1))) }
+
+	tests := []struct {
+		name      string
+		added     int
+		id        common.Hash
+		submitted bool
+	}{
+		{name: "nothing submitted", added: 0, id: id(0), submitted: false},
+		{name: "submitted", added: 3, id: id(2), submitted: true},
+		{name: "not submitted", added: 3, id: id(3), submitted: false},
+		{name: "oldest remembered", added: sequencedTxs, id: id(0), submitted: true},
+		{name: "oldest forgotten", added: sequencedTxs + 1, id: id(0), submitted: false},
+		{name: "latest remembered", added: sequencedTxs + 1, id: id(sequencedTxs), submitted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequence := &accountSequence{seen: make(map[common.Hash]struct{})}
+			for i := 0; i < tt.added; i++ {
+				sequence.add(id(i))
+			}
+			if submitted := sequence.submitted(tt.id); submitted != tt.submitted {
+				t.Fatalf("expected submitted to be %v, got %v", tt.submitted, submitted)
+			}
+			if len(sequence.recent) != len(sequence.seen) || len(sequence.recent) > sequencedTxs {
+				t.Fatalf("expected at most %d remembered transactions, got %d recent and %d seen", sequencedTxs, len(sequence.recent), len(sequence.seen))
+			}
+		})
+	}
+
+	var unsequenced *accountSequence
+	unsequenced.add(id(0))
+	if unsequenced.submitted(id(0)) {
+		t.Fatal("expected nothing to be submitted without a sequence")
+	}
+}
+
+func TestTxIDOf(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := new(transaction.Builder).ChainTag(0xf6).Gas(21000).Nonce(1).Build()
+	signature, err := crypto.Sign(unsigned.SigningHash().Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := unsigned.WithSignature(signature)
+
+	encode := func(tx *transaction.Transaction) string {
+		encoded, err := tx.Encoded()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encoded
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		id   common.Hash
+		ok   bool
+	}{
+		{name: "signed", raw: "0x" + encode(signed), id: signed.ID(), ok: true},
+		{name: "without the 0x prefix", raw: encode(signed), id: signed.ID(), ok: true},
+		{name: "unsigned", raw: "0x" + encode(unsigned), ok: false},
+		{name: "invalid hex", raw: "0xzz", ok: false},
+		{name: "not a transaction", raw: "0x0102", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := txIDOf(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("expected ok to be %v, got %v", tt.ok, ok)
+			}
+			if id != tt.id {
+				t.Fatalf("expected the ID %s, got %s", tt.id, id)
+			}
+		})
+	}
+}
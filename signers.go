@@ -0,0 +1,68 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/darrenvechain/thor-go-sdk/txmanager"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// signerScopeGlobal shares the nextSigner counter between every VU of the node.
+	signerScopeGlobal = "global"
+	// signerScopeVU gives every VU its own nextSigner counter.
+	signerScopeVU = "vu"
+)
+
+// signerCounters holds the global nextSigner counter of each node URL.
+var signerCounters sync.Map
+
+// signerCounterFor returns the nextSigner counter shared by all VUs of the node.
+func signerCounterFor(url string) *atomic.Uint64 {
+	counter, _ := signerCounters.LoadOrStore(url, new(atomic.Uint64))
+	return counter.(*atomic.Uint64)
+}
+
+// NextSigner returns the next account index in round-robin order, so that load is spread evenly
+// across the accounts. The counter is shared by all VUs unless signerScope is "vu", or "partition"
+// which also restricts every VU to its own slice of the accounts.
+func (c *Client) NextSigner() (int, error) {
+	partition, err := c.partition()
+	if err != nil {
+		return 0, err
+	}
+	return partition.start + int((c.signers.Add(1)-1)%uint64(partition.count)), nil
+}
+
+// signer returns the manager of the account at the index.
+func (c *Client) signer(index int) (*txmanager.PKManager, error) {
+	if index < 0 || index >= len(c.managers) {
+		return nil, fmt.Errorf("signer index %d is out of range [0, %d)", index, len(c.managers))
+	}
+	return c.managers[index], nil
+}
+
+// signerTags adds the account tag to the tags when tagAccountIndex is enabled and the signer is known.
+// The tag is bounded by the configured number of accounts.
+func (c *Client) signerTags(signer int, tags map[string]string) map[string]string {
+	if !c.opts.TagAccountIndex || signer < 0 {
+		return tags
+	}
+	tagged := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		tagged[k] = v
+	}
+	tagged["account"] = strconv.Itoa(signer)
+	return tagged
+}
+
+// signerIndex returns the account index of the address, or -1 when it is not a managed account.
+func (c *Client) signerIndex(address common.Address) int {
+	if index, ok := c.managed[address]; ok {
+		return index
+	}
+	return -1
+}
@@ -0,0 +1,58 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Sign signs the hex encoded 32 byte hash with the account at the index, e.g. an off-chain order, and
+// returns the hex encoded 65 byte secp256k1 signature, R || S || V with V being 0 or 1.
+func (c *Client) Sign(hash string, signer int) (string, error) {
+	digest, err := decodeHash(hash)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.signer(signer); err != nil {
+		return "", err
+	}
+
+	signature, err := crypto.Sign(digest, c.keys[signer])
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(signature), nil
+}
+
+// Recover returns the address of the account that signed the hex encoded 32 byte hash. The signature
+// is the hex encoded 65 byte R || S || V, where V is either 0 or 1, or 27 or 28.
+func (c *Client) Recover(hash string, signature string) (string, error) {
+	digest, err := decodeHash(hash)
+	if err != nil {
+		return "", err
+	}
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid signature %q, expected %d hex encoded bytes", signature, crypto.SignatureLength)
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	key, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover the signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*key).Hex(), nil
+}
+
+// decodeHash decodes a hex encoded 32 byte hash.
+func decodeHash(hash string) ([]byte, error) {
+	digest, err := hexutil.Decode(hash)
+	if err != nil || len(digest) != 32 {
+		return nil, errors.New("the hash must be 32 hex encoded bytes, 0x prefixed")
+	}
+	return digest, nil
+}
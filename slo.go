@@ -0,0 +1,69 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// sloOptions declares the service level objectives that vechain_slo_breach is computed against.
+type sloOptions struct {
+	// TimeToMine, e.g. "15s", is the longest acceptable time from submission to inclusion.
+	TimeToMine string `json:"timeToMine,omitempty"`
+	// RevertRate, e.g. 0.01, is the highest acceptable share of mined transactions that reverted.
+	RevertRate float64 `json:"revertRate,omitempty"`
+
+	timeToMine time.Duration
+}
+
+// validate checks the objectives and parses the durations.
+func (o *sloOptions) validate() error {
+	if o.TimeToMine != "" {
+		timeToMine, err := time.ParseDuration(o.TimeToMine)
+		if err != nil {
+			return fmt.Errorf("invalid slo.timeToMine: %w", err)
+		}
+		o.timeToMine = timeToMine
+	}
+	if o.RevertRate < 0 || o.RevertRate > 1 {
+		return errors.New("slo.revertRate must be between 0 and 1")
+	}
+	return nil
+}
+
+// checkTimeToMine records in vechain_slo_breach whether the time to mine breached the timeToMine objective.
+func (c *Client) checkTimeToMine(elapsed time.Duration) {
+	if c.opts.SLO == nil || c.opts.SLO.timeToMine == 0 {
+		return
+	}
+	c.pushSLO("timeToMine", elapsed > c.opts.SLO.timeToMine)
+}
+
+// checkReverted counts a mined transaction of the node, in vechain_tx_reverted and vechain_errors when it
+// reverted, and records in vechain_slo_breach whether the share of reverted transactions breached the
+// revertRate objective.
+func (c *Client) checkReverted(reverted bool) {
+	if reverted {
+		c.pushSample(c.metrics.TxReverted, 1, nil)
+		c.pushSample(c.metrics.Errors, 1, map[string]string{"class": errorReverted})
+	}
+	if c.opts.SLO == nil || c.opts.SLO.RevertRate == 0 {
+		return
+	}
+	stats := statsFor(c.opts.URL)
+	mined := stats.minedTxs.Add(1)
+	reverts := stats.revertedTxs.Load()
+	if reverted {
+		reverts = stats.revertedTxs.Add(1)
+	}
+	c.pushSLO("revertRate", float64(reverts)/float64(mined) > c.opts.SLO.RevertRate)
+}
+
+// pushSLO pushes a vechain_slo_breach sample of the objective.
+func (c *Client) pushSLO(objective string, breached bool) {
+	value := 0.0
+	if breached {
+		value = 1
+	}
+	c.pushSample(c.metrics.SLOBreach, value, map[string]string{"slo": objective})
+}
@@ -0,0 +1,84 @@
+package xk6_vechain
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// blockInterval is the length of a block slot of thor, in seconds.
+	blockInterval = 10
+	// proposerWindow is how long, in seconds, a signer that stopped signing is still expected to propose.
+	proposerWindow = 3600
+)
+
+// proposerHistory holds when each authority of a node's chain last signed a block.
+type proposerHistory struct {
+	mu         sync.Mutex
+	lastSigned map[common.Address]uint64 // block timestamp
+}
+
+// proposerHistories holds the proposerHistory of each node URL.
+var proposerHistories sync.Map
+
+// proposersFor returns the proposer history of the node, shared by every client of the node.
+func proposersFor(url string) *proposerHistory {
+	history, _ := proposerHistories.LoadOrStore(url, &proposerHistory{lastSigned: make(map[common.Address]uint64)})
+	return history.(*proposerHistory)
+}
+
+// observe records the signer of the block and returns the likely proposers of the missed slots, the
+// authorities that have not signed for the longest, or "unknown" when not enough signers were seen.
+func (h *proposerHistory) observe(block *client.Block, missed int) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	candidates := make([]common.Address, 0, len(h.lastSigned))
+	for signer, timestamp := range h.lastSigned {
+		if timestamp+proposerWindow < block.Timestamp {
+			delete(h.lastSigned, signer)
+			continue
+		}
+		if signer != block.Signer {
+			candidates = append(candidates, signer)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return h.lastSigned[candidates[i]] < h.lastSigned[candidates[j]]
+	})
+	h.lastSigned[block.Signer] = block.Timestamp
+
+	proposers := make([]string, missed)
+	for i := range proposers {
+		if i < len(candidates) {
+			proposers[i] = candidates[i].Hex()
+		} else {
+			proposers[i] = "unknown"
+		}
+	}
+	return proposers
+}
+
+// detectMissedSlots counts the slots between the blocks that no block was produced in, in
+// vechain_missed_slots, tagged with the likely proposer. The proposer of a slot is not derived from
+// the schedule of thor, which needs the seed and the authority list of the chain state. Instead, as
+// every authority is scheduled about once per round, the authorities that have not signed for the
+// longest are taken as the likely proposers, which is a heuristic and may blame the wrong authority.
+func (c *Client) detectMissedSlots(prev, block *client.Block) {
+	if block.Timestamp <= prev.Timestamp || block.Number <= prev.Number {
+		return
+	}
+	missed := int((block.Timestamp-prev.Timestamp)/blockInterval) - int(block.Number-prev.Number)
+
+	proposers := proposersFor(c.opts.URL).observe(block, max(missed, 0))
+	if missed <= 0 {
+		return
+	}
+
+	for _, proposer := range proposers {
+		c.pushSample(c.metrics.MissedSlots, 1, map[string]string{"likely_proposer": proposer})
+	}
+}
@@ -0,0 +1,113 @@
+package xk6_vechain
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// stateSampleInterval is how often the state size indicator is sampled.
+	stateSampleInterval = 30 * time.Second
+	// stateScrapeTimeout bounds every scrape, so that a stuck endpoint does not skip the next samples.
+	stateScrapeTimeout = 10 * time.Second
+)
+
+// stateClient scrapes the Prometheus endpoint of the node.
+var stateClient = &http.Client{Timeout: stateScrapeTimeout}
+
+// stateSampler samples the state size indicator of a node, shared by every client of the node.
+type stateSampler struct {
+	slot     atomic.Int64 // index of the last sampled interval, see sampleEvery
+	baseline atomic.Pointer[float64]
+}
+
+// stateSamplers holds the stateSampler of each node URL.
+var stateSamplers sync.Map
+
+func stateSamplerFor(url string) *stateSampler {
+	s, _ := stateSamplers.LoadOrStore(url, &stateSampler{})
+	return s.(*stateSampler)
+}
+
+// sampleStateGrowth periodically scrapes the stateMetric from the Prometheus endpoint of the node and
+// emits its growth since the first sample in vechain_state_growth, so that soak tests can correlate
+// throughput degradation with state growth. Each interval is sampled by a single client.
+func (c *Client) sampleStateGrowth() {
+	sampler := stateSamplerFor(c.opts.URL)
+	c.sampleEvery(stateSampleInterval, &sampler.slot, func() {
+		value, err := scrapeMetric(stateClient, c.opts.StateMetricsURL, c.opts.StateMetric)
+		if err != nil {
+			c.pushSample(c.metrics.MonitorErrors, 1, map[string]string{"monitor": "state"})
+			return
+		}
+
+		sampler.baseline.CompareAndSwap(nil, &value)
+		c.pushSample(c.metrics.StateGrowth, value-*sampler.baseline.Load(), map[string]string{
+			"indicator": c.opts.StateMetric,
+		})
+	})
+}
+
+// scrapeMetric returns the sum of every series of the metric in the Prometheus text exposition at the URL.
+func scrapeMetric(httpClient *http.Client, url string, name string) (float64, error) {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GET %s failed with status %d", url, res.StatusCode)
+	}
+
+	var (
+		sum   float64
+		found bool
+	)
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// the labels may contain spaces, so the value follows the closing brace when there are labels
+		end := strings.IndexAny(line, " {")
+		if end < 0 {
+			continue
+		}
+		metric, value := line[:end], line[end:]
+		if strings.HasPrefix(value, "{") {
+			closing := strings.LastIndex(value, "}")
+			if closing < 0 {
+				continue
+			}
+			value = value[closing+1:]
+		}
+		if metric != name {
+			continue
+		}
+
+		// drop the optional timestamp following the value
+		value, _, _ = strings.Cut(strings.TrimSpace(value), " ")
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value of %s: %w", name, err)
+		}
+		sum += v
+		found = true
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("metric %s not found at %s", name, url)
+	}
+	return sum, nil
+}
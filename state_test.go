@@ -0,0 +1,90 @@
+package xk6_vechain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScrapeMetric(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		exposition string
+		value      float64
+		err        string
+	}{
+		{
+			name:       "single series",
+			status:     http.StatusOK,
+			exposition: "# HELP state_size the size of the state\n# TYPE state_size gauge\nstate_size 1024\n",
+			value:      1024,
+		},
+		{
+			name:       "summed series",
+			status:     http.StatusOK,
+			exposition: "state_size{db=\"trie\"} 1e3\nstate_size{db=\"logs\"} 24\n",
+			value:      1024,
+		},
+		{
+			name:       "timestamp",
+			status:     http.StatusOK,
+			exposition: "state_size 1024 1700000000000\n",
+			value:      1024,
+		},
+		{
+			name:       "label with spaces",
+			status:     http.StatusOK,
+			exposition: "state_size{db=\"main trie\"} 1024\n",
+			value:      1024,
+		},
+		{
+			name:       "prefixed metric ignored",
+			status:     http.StatusOK,
+			exposition: "state_size_bytes 7\nstate_size 1024\n",
+			value:      1024,
+		},
+		{
+			name:       "not found",
+			status:     http.StatusOK,
+			exposition: "other 1\n",
+			err:        "metric state_size not found at ",
+		},
+		{
+			name:       "invalid value",
+			status:     http.StatusOK,
+			exposition: "state_size big\n",
+			err:        "invalid value of state_size: ",
+		},
+		{
+			name:   "failed request",
+			status: http.StatusServiceUnavailable,
+			err:    "GET ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.exposition))
+			}))
+			defer server.Close()
+
+			value, err := scrapeMetric(server.Client(), server.URL, "state_size")
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected an error starting with %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.value {
+				t.Fatalf("expected %v, got %v", tt.value, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package xk6_vechain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/metrics"
+)
+
+const (
+	// blockSourcePoll polls /blocks/best for new blocks.
+	blockSourcePoll = "poll"
+	// blockSourceWS subscribes to /subscriptions/block, falling back to polling when the connection fails.
+	blockSourceWS = "ws"
+	// subscriptionReadTimeout is how long the subscription waits for a block, or a ping, before failing.
+	subscriptionReadTimeout = time.Minute
+	// maxSubscriptionRetries is how many times in a row the subscription fails to reconnect before
+	// the block monitor falls back to polling.
+	maxSubscriptionRetries = 5
+	// subscriptionRetryInterval is the delay before reconnecting, multiplied by the consecutive failures.
+	subscriptionRetryInterval = time.Second
+	// maxSubscriptionGaps is how many gaps are queued for subscriptionGaps.
+	maxSubscriptionGaps = 100
+)
+
+// subscribedBlock is a block pushed by /subscriptions/block.
+type subscribedBlock struct {
+	client.Block
+	Obsolete bool `json:"obsolete"`
+}
+
+// subscriptionURL returns the WebSocket URL of the node endpoint.
+func subscriptionURL(nodeURL string, path string) string {
+	url := strings.TrimSuffix(nodeURL, "/") + path
+	if strings.HasPrefix(url, "https://") {
+		return "wss://" + strings.TrimPrefix(url, "https://")
+	}
+	return "ws://" + strings.TrimPrefix(url, "http://")
+}
+
+// subscribeBlocks feeds the blocks pushed by the node to the block monitor. A dropped connection is
+// reopened from the last block seen with the pos parameter, so that the node replays the blocks missed
+// in between, and counted in vechain_ws_reconnects. When the node cannot resume from there, e.g. because
+// the block is too old, the subscription resumes from the best block and the blocks skipped are a gap,
+// counted in vechain_ws_gap_blocks and queued for subscriptionGaps. It returns the last block seen along
+// with the error once the node cannot be reached maxSubscriptionRetries times in a row.
+func (c *Client) subscribeBlocks() (*client.Block, error) {
+	var (
+		prev     *client.Block
+		failures int
+	)
+
+	for {
+		if err := c.ctx.Err(); err != nil {
+			return prev, err
+		}
+		pos := ""
+		if prev != nil {
+			pos = prev.ID.Hex()
+		}
+
+		conn, status, err := c.dialBlocks(pos)
+		if err != nil && pos != "" && status >= http.StatusBadRequest && status < http.StatusInternalServerError {
+			c.log().WithFields(logrus.Fields{"url": c.opts.URL, "pos": pos}).WithError(err).
+				Warn("block subscription cannot resume, skipping to the best block")
+			conn, _, err = c.dialBlocks("")
+		}
+		if err != nil {
+			failures++
+			if failures > maxSubscriptionRetries {
+				return prev, err
+			}
+			c.sleep(time.Duration(failures) * subscriptionRetryInterval)
+			continue
+		}
+
+		if prev != nil {
+			c.pushSample(c.metrics.WSReconnects, 1, nil)
+		}
+		failures = 0
+
+		// closing the connection unblocks the read once the client is closed
+		stop := context.AfterFunc(c.ctx, func() { conn.Close() })
+		prev, err = c.readBlocks(conn, prev)
+		stop()
+		conn.Close()
+		c.log().WithField("url", c.opts.URL).WithError(err).Warn("block subscription dropped, reconnecting")
+	}
+}
+
+// dialBlocks opens the block subscription from the position, or from the best block when empty.
+// The status of the response is returned when the node refused the subscription.
+func (c *Client) dialBlocks(pos string) (*websocket.Conn, int, error) {
+	url := subscriptionURL(c.opts.URL, "/subscriptions/block")
+	if pos != "" {
+		url += "?pos=" + pos
+	}
+
+	conn, res, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		status := 0
+		if res != nil {
+			status = res.StatusCode
+		}
+		return nil, status, fmt.Errorf("failed to subscribe to %s: %w", url, err)
+	}
+
+	conn.SetPingHandler(func(data string) error {
+		if err := conn.SetReadDeadline(time.Now().Add(subscriptionReadTimeout)); err != nil {
+			return err
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	return conn, 0, nil
+}
+
+// readBlocks feeds the blocks read from the connection to the block monitor until it fails, returning
+// the last block seen along with the error.
+func (c *Client) readBlocks(conn *websocket.Conn, prev *client.Block) (*client.Block, error) {
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(subscriptionReadTimeout)); err != nil {
+			return prev, err
+		}
+
+		var block subscribedBlock
+		if err := conn.ReadJSON(&block); err != nil {
+			return prev, fmt.Errorf("block subscription failed: %w", err)
+		}
+		received := time.Now()
+		if block.Obsolete {
+			continue
+		}
+
+		if prev != nil && block.Number > prev.Number+1 {
+			c.recordGap(prev.Number+1, block.Number-1)
+		}
+
+		c.observeDelivery("block", block.Number, block.Timestamp, received)
+		prev = c.onBlock(prev, &block.Block)
+	}
+}
+
+// SubscriptionGap is a range of blocks the block subscription skipped and never delivered.
+type SubscriptionGap struct {
+	From   uint64 `js:"from"`
+	To     uint64 `js:"to"`
+	Blocks uint64 `js:"blocks"`
+}
+
+// recordGap counts the skipped blocks in vechain_ws_gap_blocks and queues the gap for subscriptionGaps,
+// dropping the oldest gap once maxSubscriptionGaps are queued.
+func (c *Client) recordGap(from, to uint64) {
+	gap := SubscriptionGap{From: from, To: to, Blocks: to - from + 1}
+	c.log().WithFields(logrus.Fields{"url": c.opts.URL, "from": from, "to": to}).Warn("block subscription skipped blocks")
+	c.pushSample(c.metrics.WSGapBlocks, float64(gap.Blocks), nil)
+
+	c.gaps.mu.Lock()
+	defer c.gaps.mu.Unlock()
+	if len(c.gaps.queued) >= maxSubscriptionGaps {
+		c.gaps.queued = c.gaps.queued[1:]
+	}
+	c.gaps.queued = append(c.gaps.queued, gap)
+}
+
+// SubscriptionGaps returns the gaps of the block subscription since the previous call. The subscription
+// runs outside of the iterations, so the gaps are queued for the script to check rather than pushed to it.
+func (c *Client) SubscriptionGaps() []SubscriptionGap {
+	c.gaps.mu.Lock()
+	defer c.gaps.mu.Unlock()
+	gaps := c.gaps.queued
+	c.gaps.queued = nil
+	if gaps == nil {
+		gaps = []SubscriptionGap{}
+	}
+	return gaps
+}
+
+// subscriptionGaps queues the gaps of the block subscription of a client.
+type subscriptionGaps struct {
+	mu     sync.Mutex
+	queued []SubscriptionGap
+}
+
+// observeDelivery records in vechain_ws_delivery_latency how long after the block timestamp a message of
+// the subscription was received, and, once the block was seen over REST too, how long after. The latter
+// is negative when the subscription delivered the block first.
+func (c *Client) observeDelivery(subscription string, number uint64, timestamp uint64, received time.Time) {
+	c.pushSample(c.metrics.WSDeliveryLatency, metrics.D(received.Sub(time.Unix(int64(timestamp), 0))), map[string]string{
+		"subscription": subscription,
+		"from":         "timestamp",
+	})
+
+	key := reportedBlock{url: c.opts.URL, number: number}
+	if _, loaded := wsSeen.LoadOrStore(key, received); loaded {
+		return
+	}
+	if seen, ok := restSeen.Load(key); ok {
+		c.pushRESTDeliveryLatency(received.Sub(seen.(time.Time)))
+	}
+}
+
+// observeRESTDelivery records when the block was first seen over REST, and when the subscription
+// delivered it before, how long before in vechain_ws_delivery_latency.
+func (c *Client) observeRESTDelivery(number uint64, seen time.Time) {
+	key := reportedBlock{url: c.opts.URL, number: number}
+	if _, loaded := restSeen.LoadOrStore(key, seen); loaded {
+		return
+	}
+	if received, ok := wsSeen.Load(key); ok {
+		c.pushRESTDeliveryLatency(received.(time.Time).Sub(seen))
+	}
+}
+
+// pushRESTDeliveryLatency pushes the delay between the subscription and REST delivering a block.
+func (c *Client) pushRESTDeliveryLatency(latency time.Duration) {
+	c.pushSample(c.metrics.WSDeliveryLatency, metrics.D(latency), map[string]string{
+		"subscription": "block",
+		"from":         "rest",
+	})
+}
@@ -0,0 +1,65 @@
+package xk6_vechain
+
+import (
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.k6.io/k6/js/modulestest"
+)
+
+func TestSubscriptionURL(t *testing.T) {
+	tests := []struct {
+		nodeURL  string
+		expected string
+	}{
+		{nodeURL: "http://localhost:8669", expected: "ws://localhost:8669/subscriptions/block"},
+		{nodeURL: "http://localhost:8669/", expected: "ws://localhost:8669/subscriptions/block"},
+		{nodeURL: "https://testnet.vechain.org", expected: "wss://testnet.vechain.org/subscriptions/block"},
+		{nodeURL: "https://node.example/thor/", expected: "wss://node.example/thor/subscriptions/block"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nodeURL, func(t *testing.T) {
+			if url := subscriptionURL(tt.nodeURL, "/subscriptions/block"); url != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, url)
+			}
+		})
+	}
+}
+
+func TestRecordGap(t *testing.T) {
+	tests := []struct {
+		name   string
+		gaps   int
+		queued int
+		first  uint64 // from of the first queued gap
+	}{
+		{name: "none", gaps: 0, queued: 0},
+		{name: "some", gaps: 3, queued: 3, first: 0},
+		{name: "at the cap", gaps: maxSubscriptionGaps, queued: maxSubscriptionGaps, first: 0},
+		{name: "beyond the cap", gaps: maxSubscriptionGaps + 5, queued: maxSubscriptionGaps, first: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := logrus.New()
+			logger.SetOutput(io.Discard)
+			c := &Client{vu: &modulestest.VU{}, logger: logger, opts: &options{URL: "http://localhost:8669"}}
+			for i := 0; i < tt.gaps; i++ {
+				c.recordGap(uint64(i)*10, uint64(i)*10+9)
+			}
+
+			gaps := c.SubscriptionGaps()
+			if len(gaps) != tt.queued {
+				t.Fatalf("expected %d queued gaps, got %d", tt.queued, len(gaps))
+			}
+			if len(gaps) > 0 && (gaps[0].From != tt.first || gaps[0].Blocks != 10) {
+				t.Fatalf("expected the oldest gap to span 10 blocks from %d, got %+v", tt.first, gaps[0])
+			}
+			if again := c.SubscriptionGaps(); len(again) != 0 {
+				t.Fatalf("expected the gaps to be returned once, got %d again", len(again))
+			}
+		})
+	}
+}
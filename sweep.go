@@ -0,0 +1,140 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// SweepResult is the outcome of sweep. VET and VTHO are the amounts swept, in wei as decimal strings.
+type SweepResult struct {
+	Swept int `js:"swept"`
+	// Skipped counts the accounts holding nothing, or too little VTHO to pay for their sweep.
+	Skipped int    `js:"skipped"`
+	Failed  int    `js:"failed"`
+	VET     string `js:"vet"`
+	VTHO    string `js:"vtho"`
+}
+
+// Sweep drains the VET and VTHO of every other account of the client into the account at the index,
+// e.g. at the end of a test on a long-lived testnet. Every account pays for its own sweep, so what is
+// left is the VTHO generated while the sweep is mined. It waits until every sweep is mined.
+func (c *Client) Sweep(to int) (*SweepResult, error) {
+	recipient, err := c.signer(to)
+	if err != nil {
+		return nil, err
+	}
+	baseGasPrice, err := c.baseGasPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu     sync.Mutex
+		result = &SweepResult{}
+		vet    = new(big.Int)
+		vtho   = new(big.Int)
+		wg     sync.WaitGroup
+		slots  = make(chan struct{}, exportConcurrency)
+	)
+	for i := range c.managers {
+		if i == to {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			sweptVET, sweptVTHO, err := c.sweepAccount(i, recipient.Address(), baseGasPrice)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				c.log().WithFields(logrus.Fields{"url": c.opts.URL, "account": i}).WithError(err).Warn("sweep failed")
+				result.Failed++
+			case sweptVET == nil:
+				result.Skipped++
+			default:
+				result.Swept++
+				vet.Add(vet, sweptVET)
+				vtho.Add(vtho, sweptVTHO)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	result.VET = vet.String()
+	result.VTHO = vtho.String()
+	return result, nil
+}
+
+// sweepAccount transfers the VET and VTHO of the account to the recipient, keeping the VTHO the
+// transaction costs, and returns the amounts swept, or nils when there is nothing the account can sweep.
+func (c *Client) sweepAccount(signer int, recipient common.Address, baseGasPrice *big.Int) (*big.Int, *big.Int, error) {
+	address := c.managers[signer].Address()
+	account, err := c.thor.Account(address).Get()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch balances of %s: %w", address, err)
+	}
+	vet, energy := account.Balance.ToInt(), account.Energy.ToInt()
+	if energy.Sign() == 0 {
+		return nil, nil, nil
+	}
+
+	// the gas does not depend on the VTHO amount, so it is simulated with the whole balance
+	clauses, err := c.sweepClauses(recipient, vet, energy)
+	if err != nil {
+		return nil, nil, err
+	}
+	simulation, err := c.thor.Transactor(clauses, address).Simulate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to simulate the sweep of %s: %w", address, err)
+	}
+	if !simulation.IsSuccess() {
+		return nil, nil, fmt.Errorf("the sweep of %s would fail: %s", address, simulation.VMError())
+	}
+	gas := simulation.TotalGas()
+
+	fee := new(big.Int).Mul(baseGasPrice, new(big.Int).SetUint64(gas))
+	if energy.Cmp(fee) <= 0 {
+		return nil, nil, nil
+	}
+	vtho := new(big.Int).Sub(energy, fee)
+
+	if clauses, err = c.sweepClauses(recipient, vet, vtho); err != nil {
+		return nil, nil, err
+	}
+	raw, err := c.signClauses(clauses, signer, txParams{Gas: gas})
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := c.sendAndWait(raw, defaultMineTimeout, nil); err != nil {
+		return nil, nil, fmt.Errorf("the sweep of %s failed: %w", address, err)
+	}
+	return vet, vtho, nil
+}
+
+// sweepClauses returns the clauses transferring the VET and the VTHO to the recipient, skipping zero amounts.
+func (c *Client) sweepClauses(recipient common.Address, vet, vtho *big.Int) ([]*transaction.Clause, error) {
+	clauses := make([]*transaction.Clause, 0, 2)
+	if vet.Sign() > 0 {
+		clauses = append(clauses, transaction.NewClause(&recipient).WithValue(vet))
+	}
+	if vtho.Sign() > 0 {
+		clause, err := builtins.VTHO.Load(c.thor).AsClause("transfer", recipient, vtho)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses, nil
+}
@@ -0,0 +1,187 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// signerRandom signs every transaction of the template with a random account.
+	signerRandom = "random"
+	// signerRoundRobin signs the transactions of the template with the accounts in turn, see nextSigner.
+	signerRoundRobin = "roundRobin"
+	// signerFixed signs every transaction of the template with the same account.
+	signerFixed = "fixed"
+)
+
+// templateOptions defines a transaction template.
+type templateOptions struct {
+	Clauses []map[string]interface{} `json:"clauses"`
+	// Gas is the gas limit of the transactions, estimated once at definition when 0.
+	Gas uint64 `json:"gas,omitempty"`
+	// SignerStrategy is how the signer is picked, either "random", "roundRobin", or "fixed".
+	SignerStrategy string `json:"signerStrategy,omitempty"`
+	// Signer is the account index used by the "fixed" strategy.
+	Signer int `json:"signer,omitempty"`
+}
+
+// templateOverrides are the per-send changes to a template. Clauses maps a clause index to the fields
+// of the clause that are replaced.
+type templateOverrides struct {
+	Signer  *int                              `json:"signer,omitempty"`
+	Clauses map[string]map[string]interface{} `json:"clauses,omitempty"`
+	txParams
+}
+
+// txTemplate is a defined template with its clauses built.
+type txTemplate struct {
+	name     string
+	clauses  []*transaction.Clause
+	gas      uint64
+	strategy string
+	signer   int
+}
+
+// templates holds the templates defined on every client, by name.
+type templates struct {
+	mu     sync.RWMutex
+	byName map[string]*txTemplate
+}
+
+// DefineTemplate builds the clauses of a transaction template once, and estimates its gas unless given,
+// so that iterations only pass small overrides to sendTemplate.
+func (c *Client) DefineTemplate(name string, options map[string]interface{}) error {
+	var opts templateOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return err
+	}
+
+	clauses, err := parseClauses(opts.Clauses)
+	if err != nil {
+		return err
+	}
+
+	switch opts.SignerStrategy {
+	case "":
+		opts.SignerStrategy = signerRandom
+	case signerRandom, signerRoundRobin, signerFixed:
+	default:
+		return fmt.Errorf("unknown signerStrategy %q", opts.SignerStrategy)
+	}
+	manager, err := c.signer(opts.Signer)
+	if err != nil {
+		return err
+	}
+
+	if opts.Gas == 0 {
+		simulation, err := c.thor.Transactor(clauses, manager.Address()).Simulate()
+		if err != nil {
+			return fmt.Errorf("failed to estimate the gas of template %s: %w", name, err)
+		}
+		if !simulation.IsSuccess() {
+			return fmt.Errorf("template %s reverts: %s", name, simulation.VMError())
+		}
+		opts.Gas = simulation.TotalGas()
+	}
+
+	c.templates.mu.Lock()
+	defer c.templates.mu.Unlock()
+	if c.templates.byName == nil {
+		c.templates.byName = make(map[string]*txTemplate)
+	}
+	c.templates.byName[name] = &txTemplate{
+		name:     name,
+		clauses:  clauses,
+		gas:      opts.Gas,
+		strategy: opts.SignerStrategy,
+		signer:   opts.Signer,
+	}
+	return nil
+}
+
+// SendTemplate sends a transaction of the template with the overrides applied, returning its ID.
+func (c *Client) SendTemplate(name string, overrides map[string]interface{}) (string, error) {
+	c.templates.mu.RLock()
+	template, ok := c.templates.byName[name]
+	c.templates.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("template %s is not defined", name)
+	}
+
+	var opts templateOverrides
+	if err := decodeOptions(overrides, &opts); err != nil {
+		return "", err
+	}
+
+	clauses, err := template.apply(opts.Clauses)
+	if err != nil {
+		return "", err
+	}
+
+	params := opts.txParams
+	if params.Gas == 0 {
+		params.Gas = template.gas
+	}
+
+	var signer int
+	switch {
+	case opts.Signer != nil:
+		signer = *opts.Signer
+	case template.strategy == signerRoundRobin:
+		signer, err = c.NextSigner()
+	case template.strategy == signerFixed:
+		signer = template.signer
+	default:
+		signer, err = c.randomSigner()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	id, err := c.sendClauses(clauses, signer, params, "template "+name)
+	if err != nil {
+		return "", err
+	}
+	return id.Hex(), nil
+}
+
+// apply returns the clauses of the template with the overridden fields replaced. The template
+// clauses are shared, so only the overridden clauses are copied.
+func (t *txTemplate) apply(overrides map[string]map[string]interface{}) ([]*transaction.Clause, error) {
+	if len(overrides) == 0 {
+		return t.clauses, nil
+	}
+
+	clauses := append([]*transaction.Clause(nil), t.clauses...)
+	for key, override := range overrides {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(clauses) {
+			return nil, fmt.Errorf("invalid clause index %q of template %s", key, t.name)
+		}
+
+		// fill the fields that are not overridden from the template clause
+		merged := map[string]interface{}{
+			"to":    nil,
+			"value": "0x" + clauses[index].Value().Text(16),
+			"data":  hexutil.Encode(clauses[index].Data()),
+		}
+		if to := clauses[index].To(); to != nil {
+			merged["to"] = to.Hex()
+		}
+		for field, value := range override {
+			merged[field] = value
+		}
+
+		parsed, err := parseClauses([]map[string]interface{}{merged})
+		if err != nil {
+			return nil, fmt.Errorf("invalid override of clause %d of template %s: %w", index, t.name, err)
+		}
+		clauses[index] = parsed[0]
+	}
+
+	return clauses, nil
+}
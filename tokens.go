@@ -0,0 +1,66 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/darrenvechain/thor-go-sdk/builtins"
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// TokenBalance returns the balance of the holder in the VIP-180 token at the address, as a decimal string.
+func (c *Client) TokenBalance(token string, holder string) (string, error) {
+	balances, err := c.TokenBalances(token, []string{holder})
+	if err != nil {
+		return "", err
+	}
+	return balances[0], nil
+}
+
+// TokenBalances returns the balances of the holders in the VIP-180 token at the address, as decimal
+// strings in the order of the holders. The balanceOf calls are batched into multi-clause simulations,
+// so that thousands of holders only take a few requests.
+func (c *Client) TokenBalances(token string, holders []string) ([]string, error) {
+	tokenAddress, err := parseAddress(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// VTHO implements VIP-180, so its ABI encodes balanceOf for any token
+	vip180 := builtins.VTHO.ABI
+	clauses := make([]*transaction.Clause, len(holders))
+	for i, holder := range holders {
+		address, err := parseAddress(holder)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holder at index %d: %w", i, err)
+		}
+		data, err := vip180.Pack("balanceOf", address)
+		if err != nil {
+			return nil, err
+		}
+		clauses[i] = transaction.NewClause(&tokenAddress).WithData(data).WithValue(big.NewInt(0))
+	}
+
+	inspections, err := c.inspectEach(clauses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balances of %s: %w", tokenAddress.Hex(), err)
+	}
+
+	balances := make([]string, len(holders))
+	for i, inspection := range inspections {
+		if inspection.Reverted || inspection.VmError != "" {
+			return nil, fmt.Errorf("balanceOf %s of %s reverted: %s", holders[i], tokenAddress.Hex(), inspection.VmError)
+		}
+		data, err := hexutil.Decode(inspection.Data)
+		if err != nil {
+			return nil, err
+		}
+		var balance *big.Int
+		if err := vip180.UnpackIntoInterface(&balance, "balanceOf", data); err != nil {
+			return nil, fmt.Errorf("invalid balanceOf of %s: %w", tokenAddress.Hex(), err)
+		}
+		balances[i] = balance.String()
+	}
+	return balances, nil
+}
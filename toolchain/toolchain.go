@@ -2,11 +2,15 @@ package toolchain
 
 import (
 	_ "embed"
+	"encoding/binary"
 	"errors"
-	"log/slog"
+	"fmt"
+	"math"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/darrenvechain/thor-go-sdk/crypto/hash"
 	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
 	"github.com/darrenvechain/thor-go-sdk/thorgo"
 	"github.com/darrenvechain/thor-go-sdk/thorgo/accounts"
@@ -26,11 +30,10 @@ var (
 	toolchainABI, abiErr = abi.JSON(strings.NewReader(ABI))
 )
 
-func NewTransaction(thor *thorgo.Thor, managers []*txmanager.PKManager, address common.Address) (string, error) {
-	manager := random.Element(managers)
-
+// Clauses returns the clauses of a toolchain transaction to the contract at the address.
+func Clauses(thor *thorgo.Thor, address common.Address) ([]*transaction.Clause, error) {
 	if abiErr != nil {
-		return "", abiErr
+		return nil, abiErr
 	}
 	contract := thor.Account(address).Contract(&toolchainABI)
 
@@ -42,12 +45,35 @@ func NewTransaction(thor *thorgo.Thor, managers []*txmanager.PKManager, address
 		c := [32]byte(random.Bytes(32))
 		clause, err := contract.AsClause("setBytes32", a, b, c)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		clauses[i] = clause
 	}
+	return clauses, nil
+}
+
+// EventClause returns a clause making the contract at the address emit a ToolchainEvent whose second
+// indexed topic is the id, so that the event can be told apart from the others.
+func EventClause(thor *thorgo.Thor, address common.Address, id common.Hash) (*transaction.Clause, error) {
+	if abiErr != nil {
+		return nil, abiErr
+	}
+	return thor.Account(address).Contract(&toolchainABI).AsClause("setBytes32", random.Uint8(), [32]byte(id), [32]byte(random.Bytes(32)))
+}
+
+// EventTopic returns the topic of ToolchainEvent.
+func EventTopic() common.Hash {
+	return toolchainABI.Events["ToolchainEvent"].ID
+}
+
+// NewTransaction builds a toolchain transaction signed by the manager and returns it hex encoded.
+func NewTransaction(thor *thorgo.Thor, manager *txmanager.PKManager, address common.Address) (string, error) {
+	clauses, err := Clauses(thor, address)
+	if err != nil {
+		return "", err
+	}
 
-	tx, err := thor.Transactor(clauses, manager.Address()).Build()
+	tx, err := thor.Transactor(clauses, manager.Address()).Nonce(random.Nonce()).Build()
 	if err != nil {
 		return "", err
 	}
@@ -66,16 +92,25 @@ func NewTransaction(thor *thorgo.Thor, managers []*txmanager.PKManager, address
 	return encoded, nil
 }
 
-func Deploy(thor *thorgo.Thor, managers []*txmanager.PKManager, amount int) ([]*accounts.Contract, error) {
-	contracts := make([]*accounts.Contract, 0, amount)
+// Deployment is a deployed toolchain contract along with the cost of deploying it.
+type Deployment struct {
+	Contract *accounts.Contract
+	TxID     common.Hash
+	Duration time.Duration
+	GasUsed  uint64
+}
+
+func Deploy(thor *thorgo.Thor, managers []*txmanager.PKManager, amount int) ([]*Deployment, error) {
+	deployments := make([]*Deployment, 0, amount)
 	if abiErr != nil {
 		return nil, abiErr
 	}
 	deployer := thor.Deployer(common.Hex2Bytes(Bytecode), &toolchainABI)
 
 	var (
-		mu sync.Mutex // mutex to protect concurrent writes
-		wg sync.WaitGroup
+		mu   sync.Mutex // mutex to protect concurrent writes
+		wg   sync.WaitGroup
+		errs []error
 	)
 
 	for i := range amount {
@@ -84,24 +119,151 @@ func Deploy(thor *thorgo.Thor, managers []*txmanager.PKManager, amount int) ([]*
 		go func(m *txmanager.PKManager) {
 			defer wg.Done()
 
+			started := time.Now()
 			contract, txID, err := deployer.Deploy(manager)
 			if err != nil {
-				slog.Error("failed to deploy toolchain contract", "error", err, "txID", txID)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to deploy toolchain contract %s: %w", txID.Hex(), err))
+				mu.Unlock()
+				return
+			}
+			duration := time.Since(started)
+
+			receipt, err := thor.Transaction(txID).Receipt()
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("failed to fetch the receipt of toolchain deployment %s: %w", txID.Hex(), err))
+				mu.Unlock()
 				return
 			}
 
 			mu.Lock()
-			contracts = append(contracts, contract)
+			deployments = append(deployments, &Deployment{
+				Contract: contract,
+				TxID:     txID,
+				Duration: duration,
+				GasUsed:  receipt.GasUsed,
+			})
 			mu.Unlock()
 		}(manager)
 	}
 
 	wg.Wait()
 
-	if len(contracts) != amount {
-		slog.Error("failed to deploy all contracts")
-		return nil, errors.New("failed to deploy all contracts")
+	if len(deployments) != amount {
+		return nil, fmt.Errorf("failed to deploy all contracts: %w", errors.Join(errs...))
+	}
+
+	return deployments, nil
+}
+
+// DeterministicGas is the gas limit of deterministic deployments. It is fixed rather than estimated,
+// since it is part of the transaction ID.
+const DeterministicGas = 1_000_000
+
+// DeterministicTransaction builds the deployment of the toolchain contract by the deployer with every
+// field fixed: it references the genesis block, never expires and has a zero nonce. As long as the
+// deployer never sent it before, on a given chain the transaction, and so the address of the contract,
+// is the same on every run. It returns the signed transaction and the address the contract will have.
+func DeterministicTransaction(thor *thorgo.Thor, deployer *txmanager.PKManager) (*transaction.Transaction, common.Address, error) {
+	genesis := thor.Client.GenesisBlock()
+	tx := new(transaction.Builder).
+		ChainTag(thor.Client.ChainTag()).
+		BlockRef(transaction.NewBlockRefFromID(genesis.ID)).
+		Expiration(math.MaxUint32).
+		Gas(DeterministicGas).
+		Nonce(0).
+		Clause(transaction.NewClause(nil).WithData(common.Hex2Bytes(Bytecode))).
+		Build()
+
+	signature, err := deployer.SignTransaction(tx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	tx = tx.WithSignature(signature)
+
+	return tx, contractAddress(tx.ID(), 0, 0), nil
+}
+
+// contractAddress returns the address thor gives to the contract created by the clause of the transaction.
+func contractAddress(txID common.Hash, clauseIndex uint32, creationCount uint32) common.Address {
+	var clause, creation [4]byte
+	binary.BigEndian.PutUint32(clause[:], clauseIndex)
+	binary.BigEndian.PutUint32(creation[:], creationCount)
+	return common.BytesToAddress(hash.Blake2b(txID[:], clause[:], creation[:]).Bytes())
+}
+
+// DeployDeterministic deploys a toolchain contract from each deployer with DeterministicTransaction.
+// Contracts already deployed by an earlier run are reused, with a zero duration and gas.
+func DeployDeterministic(thor *thorgo.Thor, deployers []*txmanager.PKManager) ([]*Deployment, error) {
+	if abiErr != nil {
+		return nil, abiErr
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	deployments := make([]*Deployment, len(deployers))
+
+	for i, deployer := range deployers {
+		wg.Add(1)
+		go func(i int, deployer *txmanager.PKManager) {
+			defer wg.Done()
+
+			deployment, err := deployDeterministic(thor, deployer)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to deploy toolchain contract from %s: %w", deployer.Address(), err)
+				}
+				mu.Unlock()
+				return
+			}
+			deployments[i] = deployment
+		}(i, deployer)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return deployments, nil
+}
+
+// deployDeterministic sends the deterministic deployment of the deployer, unless it is already on chain.
+func deployDeterministic(thor *thorgo.Thor, deployer *txmanager.PKManager) (*Deployment, error) {
+	tx, address, err := DeterministicTransaction(thor, deployer)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := thor.Client.AccountCode(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimPrefix(code.Code, "0x")) > 0 {
+		return &Deployment{Contract: thor.Account(address).Contract(&toolchainABI), TxID: tx.ID()}, nil
+	}
+
+	started := time.Now()
+	if _, err := thor.Client.SendTransaction(tx); err != nil {
+		return nil, err
+	}
+	receipt, err := thor.Transaction(tx.ID()).Wait()
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Reverted {
+		return nil, errors.New("deployment reverted")
 	}
 
-	return contracts, nil
+	return &Deployment{
+		Contract: thor.Account(address).Contract(&toolchainABI),
+		TxID:     tx.ID(),
+		Duration: time.Since(started),
+		GasUsed:  receipt.GasUsed,
+	}, nil
 }
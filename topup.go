@@ -0,0 +1,110 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// topUpOptions configures fundIfBelow.
+type topUpOptions struct {
+	// Start is the index of the first account topped up, the accounts before it are the funders.
+	// 1 when not set, so that the first account funds the rest.
+	Start int `json:"start,omitempty"`
+}
+
+// TopUpResult is the outcome of fundIfBelow. VET and VTHO are the amounts sent, in wei as decimal strings.
+type TopUpResult struct {
+	Checked  int    `js:"checked"`
+	ToppedUp int    `js:"toppedUp"`
+	VET      string `js:"vet"`
+	VTHO     string `js:"vtho"`
+}
+
+// FundIfBelow tops up the accounts after the start index whose VET or VTHO balance is below the
+// threshold, sending the difference to the target, so that re-running the funding of a persistent
+// testnet does not keep piling funds onto accounts that already hold enough. The threshold and target
+// are amounts of whole tokens as decimals, e.g. {vet: "10", vtho: "500"}, and a token is only checked
+// when it has a threshold.
+func (c *Client) FundIfBelow(threshold, target, options map[string]interface{}) (*TopUpResult, error) {
+	var (
+		thresholds, targets tokenAmounts
+		opts                topUpOptions
+	)
+	if err := decodeOptions(threshold, &thresholds); err != nil {
+		return nil, fmt.Errorf("invalid threshold: %w", err)
+	}
+	if err := decodeOptions(target, &targets); err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Start == 0 {
+		opts.Start = 1
+	}
+	if opts.Start < 0 || opts.Start > len(c.managers) {
+		return nil, fmt.Errorf("start must be between 1 and %d", len(c.managers))
+	}
+
+	vetThreshold, vthoThreshold, err := thresholds.wei()
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold: %w", err)
+	}
+	vetTarget, vthoTarget, err := targets.wei()
+	if err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	if vetThreshold.Sign() == 0 && vthoThreshold.Sign() == 0 {
+		return nil, errors.New("nothing to top up, set the vet or vtho threshold")
+	}
+	if vetTarget.Cmp(vetThreshold) < 0 || vthoTarget.Cmp(vthoThreshold) < 0 {
+		return nil, errors.New("the target must be at least the threshold of every token")
+	}
+
+	accounts, err := c.balances(opts.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		transfers = make([]transfer, 0, len(accounts))
+		vet       = new(big.Int)
+		vtho      = new(big.Int)
+	)
+	for i, account := range accounts {
+		t := transfer{
+			to:   c.managers[opts.Start+i].Address(),
+			vet:  topUp(account.Balance.ToInt(), vetThreshold, vetTarget),
+			vtho: topUp(account.Energy.ToInt(), vthoThreshold, vthoTarget),
+		}
+		if t.vet.Sign() == 0 && t.vtho.Sign() == 0 {
+			continue
+		}
+		transfers = append(transfers, t)
+		vet.Add(vet, t.vet)
+		vtho.Add(vtho, t.vtho)
+	}
+
+	result := &TopUpResult{Checked: len(accounts), ToppedUp: len(transfers), VET: vet.String(), VTHO: vtho.String()}
+	if len(transfers) == 0 {
+		return result, nil
+	}
+
+	plan, err := c.planTransfers(transfers, opts.Start)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.fund(plan); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// topUp returns the amount that brings the balance to the target when it is below the threshold, or zero.
+func topUp(balance, threshold, target *big.Int) *big.Int {
+	if balance.Cmp(threshold) >= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(target, balance)
+}
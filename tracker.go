@@ -0,0 +1,318 @@
+package xk6_vechain
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"go.k6.io/k6/metrics"
+)
+
+// trackedTxTTL is how long a transaction is tracked before it is evicted, e.g. because it expired
+// without being included or its block never reached the confirmation depth.
+const trackedTxTTL = 10 * time.Minute
+
+// finalizingTxTTL is how long an included transaction is tracked until its block is finalized, which
+// takes several epochs.
+const finalizingTxTTL = 2 * time.Hour
+
+// trackedTx is a transaction followed by the txTracker.
+type trackedTx struct {
+	submitted time.Time
+	signer    int    // account index of the sender, or -1 when unknown
+	gas       uint64 // gas limit of the transaction
+	block     uint64 // number of the including block, once included
+	// slot is the in-flight slot of the transaction, freed once it is included or evicted
+	slot *inFlightSlot
+	// background is set for fire-and-forget transactions, whose inclusion is resolved by the tracker
+	background bool
+	// orphaned is set once the including block was orphaned by a reorg, after which the transaction
+	// is pending again, its in-flight slot already freed and its time to mine already reported
+	orphaned bool
+}
+
+// txTracker follows the transactions sent by a client from submission until they are confirmed.
+type txTracker struct {
+	mu       sync.Mutex
+	pending  map[common.Hash]trackedTx
+	included map[common.Hash]trackedTx
+	// finalizing holds the included transactions waiting for finality, when it is tracked
+	finalizing map[common.Hash]trackedTx
+	stats      *chainStats
+}
+
+// newTxTracker returns a tracker, which also follows the included transactions until their block is
+// finalized when trackFinality is set.
+func newTxTracker(stats *chainStats, trackFinality bool) *txTracker {
+	t := &txTracker{
+		pending:  make(map[common.Hash]trackedTx),
+		included: make(map[common.Hash]trackedTx),
+		stats:    stats,
+	}
+	if trackFinality {
+		t.finalizing = make(map[common.Hash]trackedTx)
+	}
+	return t
+}
+
+// add starts tracking a submitted transaction.
+func (t *txTracker) add(id common.Hash, submitted time.Time, signer int, gas uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending[id] = trackedTx{submitted: submitted, signer: signer, gas: gas}
+	t.stats.pendingTxs.Add(1)
+}
+
+// hold attaches the in-flight slot to the pending transaction, to be freed once it is included or
+// evicted. It reports false when the transaction is no longer pending.
+func (t *txTracker) hold(id common.Hash, slot *inFlightSlot) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tx, ok := t.pending[id]
+	if ok {
+		tx.slot = slot
+		t.pending[id] = tx
+	}
+	return ok
+}
+
+// resolveInBackground marks a pending transaction as fire-and-forget, so that the tracker reports its
+// time to mine once included, or that it was never mined once evicted.
+func (t *txTracker) resolveInBackground(id common.Hash) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tx, ok := t.pending[id]; ok {
+		tx.background = true
+		t.pending[id] = tx
+	}
+}
+
+// active reports whether any transaction is still waiting for inclusion or confirmation.
+func (t *txTracker) active() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending) > 0 || len(t.included) > 0 || len(t.finalizing) > 0
+}
+
+// include marks the pending transactions of the block as included and returns them, along with
+// the gas of the transactions still pending.
+func (t *txTracker) include(block *client.Block) ([]trackedTx, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	included := make([]trackedTx, 0)
+	for _, id := range block.Transactions {
+		if tx, ok := t.pending[id]; ok {
+			delete(t.pending, id)
+			tx.block = block.Number
+			t.included[id] = tx
+			if t.finalizing != nil {
+				t.finalizing[id] = tx
+			}
+			t.stats.pendingTxs.Add(-1)
+			t.stats.includedTxs.Add(1)
+			included = append(included, tx)
+		}
+	}
+
+	var pendingGas uint64
+	for _, tx := range t.pending {
+		pendingGas += tx.gas
+	}
+	return included, pendingGas
+}
+
+// orphan tracks the included transactions whose block is above the fork as pending again, as their
+// block is no longer part of the chain. The ones already confirmed are no longer followed for finality.
+func (t *txTracker) orphan(fork uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, tx := range t.included {
+		if tx.block > fork {
+			delete(t.included, id)
+			t.stats.includedTxs.Add(-1)
+			tx.block = 0
+			tx.orphaned = true
+			t.pending[id] = tx
+			t.stats.pendingTxs.Add(1)
+		}
+	}
+	for id, tx := range t.finalizing {
+		if tx.block > fork {
+			delete(t.finalizing, id)
+		}
+	}
+}
+
+// confirm removes and returns the included transactions whose block is at or below the given number.
+func (t *txTracker) confirm(number uint64) []trackedTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	confirmed := make([]trackedTx, 0)
+	for id, tx := range t.included {
+		if tx.block <= number {
+			confirmed = append(confirmed, tx)
+			delete(t.included, id)
+			t.stats.includedTxs.Add(-1)
+		}
+	}
+	return confirmed
+}
+
+// finalize removes and returns the transactions waiting for finality whose block is at or below the
+// finalized block number.
+func (t *txTracker) finalize(finalized uint64) []trackedTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	finalizedTxs := make([]trackedTx, 0)
+	for id, tx := range t.finalizing {
+		if tx.block <= finalized {
+			finalizedTxs = append(finalizedTxs, tx)
+			delete(t.finalizing, id)
+		}
+	}
+	return finalizedTxs
+}
+
+// evict stops tracking the transactions submitted more than trackedTxTTL ago and returns the
+// evicted transactions that were never included.
+func (t *txTracker) evict(now time.Time) []trackedTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	evicted := make([]trackedTx, 0)
+	for id, tx := range t.pending {
+		if now.Sub(tx.submitted) > trackedTxTTL {
+			delete(t.pending, id)
+			t.stats.pendingTxs.Add(-1)
+			evicted = append(evicted, tx)
+		}
+	}
+	for id, tx := range t.included {
+		if now.Sub(tx.submitted) > trackedTxTTL {
+			delete(t.included, id)
+			t.stats.includedTxs.Add(-1)
+		}
+	}
+	for id, tx := range t.finalizing {
+		if now.Sub(tx.submitted) > finalizingTxTTL {
+			delete(t.finalizing, id)
+		}
+	}
+	return evicted
+}
+
+// blockInclusion is the gas the clients of a node submitted that a block included, and the gas they
+// submitted that it left pending.
+type blockInclusion struct {
+	included uint64
+	pending  uint64
+}
+
+// includeBlock feeds the block to the tracker, frees the in-flight slots of the included transactions,
+// and adds the gas submitted by the client that the block included or left pending to the inclusion.
+func (c *Client) includeBlock(block *client.Block, inclusion map[uint64]*blockInclusion) {
+	included, pendingGas := c.tracker.include(block)
+
+	var includedGas uint64
+	for _, tx := range included {
+		includedGas += tx.gas
+		c.releaseInFlight(tx.slot)
+		if tx.orphaned {
+			continue
+		}
+		if tx.background {
+			elapsed := time.Since(tx.submitted)
+			c.pushSample(c.metrics.TimeToMine, metrics.D(elapsed), c.signerTags(tx.signer, map[string]string{"mode": "background"}))
+			c.checkTimeToMine(elapsed)
+		}
+	}
+
+	gas, ok := inclusion[block.Number]
+	if !ok {
+		gas = &blockInclusion{}
+		inclusion[block.Number] = gas
+	}
+	gas.included += includedGas
+	gas.pending += pendingGas
+}
+
+// reportInclusion reports, once per block, the share of the gas submitted by the clients of the node
+// that the block included, the rest being left pending.
+func (c *Client) reportInclusion(inclusion map[uint64]*blockInclusion) {
+	numbers := make([]uint64, 0, len(inclusion))
+	for number := range inclusion {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	for _, number := range numbers {
+		gas := inclusion[number]
+		if submitted := gas.included + gas.pending; submitted > 0 {
+			c.pushSample(c.metrics.InclusionShare, float64(gas.included)/float64(submitted)*100, nil)
+		}
+	}
+}
+
+// trackBlocks feeds the blocks after from, up to and including best, to the tracker and
+// increments vechain_tx_confirmed for every transaction that reached the configured depth. With
+// trackFinality, the time from submission until the block of a transaction is finalized is recorded
+// in vechain_time_to_finality. The gas included by every block is added to the inclusion.
+func (c *Client) trackBlocks(from uint64, best *client.Block, inclusion map[uint64]*blockInclusion) {
+	for _, tx := range c.tracker.evict(time.Now()) {
+		c.releaseInFlight(tx.slot)
+		if tx.background {
+			c.pushSample(c.metrics.TxNotMined, 1, c.signerTags(tx.signer, map[string]string{"reason": "evicted"}))
+		}
+	}
+
+	if !c.tracker.active() {
+		return
+	}
+
+	// the poller may skip blocks, so fetch the ones in between
+	for n := from + 1; n < best.Number; n++ {
+		block, err := c.thor.Blocks.ByNumber(n)
+		if err != nil {
+			continue
+		}
+		c.includeBlock(block, inclusion)
+	}
+	c.includeBlock(best, inclusion)
+
+	var finalized *client.Block
+	if c.opts.ConfirmFinalized || c.opts.TrackFinality {
+		var err error
+		if finalized, err = c.thor.Blocks.Finalized(); err != nil {
+			return
+		}
+	}
+
+	if c.opts.TrackFinality {
+		for _, tx := range c.tracker.finalize(finalized.Number) {
+			c.pushSample(c.metrics.TimeToFinality, metrics.D(time.Since(tx.submitted)), c.signerTags(tx.signer, nil))
+		}
+	}
+
+	var (
+		confirmedAt uint64
+		depth       string
+	)
+	if c.opts.ConfirmFinalized {
+		confirmedAt = finalized.Number
+		depth = "finalized"
+	} else {
+		// a transaction in the best block has a depth of 1
+		blocksDeep := uint64(c.opts.Confirmations)
+		if best.Number+1 < blocksDeep {
+			return
+		}
+		confirmedAt = best.Number + 1 - blocksDeep
+		depth = strconv.Itoa(c.opts.Confirmations)
+	}
+
+	for _, tx := range c.tracker.confirm(confirmedAt) {
+		c.pushSample(c.metrics.TxConfirmed, 1, c.signerTags(tx.signer, map[string]string{"depth": depth}))
+	}
+}
@@ -0,0 +1,146 @@
+package xk6_vechain
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// trackerTxID returns the ID of the i-th transaction of a tracker test.
+func trackerTxID(i int) common.Hash {
+	return common.BigToHash(big.NewInt(int64(i + 1)))
+}
+
+// newTestTracker returns a tracker following the pending transactions, whose gas is 1000 times
+// their index plus one, with the transactions of the blocks included.
+func newTestTracker(pending int, blocks ...*client.Block) *txTracker {
+	tracker := newTxTracker(&chainStats{}, false)
+	for i := 0; i < pending; i++ {
+		tracker.add(trackerTxID(i), time.Now(), i, uint64(i+1)*1000)
+	}
+	for _, block := range blocks {
+		tracker.include(block)
+	}
+	return tracker
+}
+
+func TestTxTrackerInclude(t *testing.T) {
+	tests := []struct {
+		name       string
+		txs        []common.Hash
+		included   int
+		pendingGas uint64
+	}{
+		{name: "empty block", txs: nil, included: 0, pendingGas: 6000},
+		{name: "foreign transactions", txs: []common.Hash{trackerTxID(10)}, included: 0, pendingGas: 6000},
+		{name: "some", txs: []common.Hash{trackerTxID(0), trackerTxID(10), trackerTxID(2)}, included: 2, pendingGas: 2000},
+		{name: "all", txs: []common.Hash{trackerTxID(0), trackerTxID(1), trackerTxID(2)}, included: 3, pendingGas: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTestTracker(3)
+			included, pendingGas := tracker.include(&client.Block{Number: 5, Transactions: tt.txs})
+			if len(included) != tt.included {
+				t.Fatalf("expected %d included transactions, got %d", tt.included, len(included))
+			}
+			for _, tx := range included {
+				if tx.block != 5 {
+					t.Fatalf("expected the transactions to be included in block 5, got %d", tx.block)
+				}
+			}
+			if pendingGas != tt.pendingGas {
+				t.Fatalf("expected %d gas pending, got %d", tt.pendingGas, pendingGas)
+			}
+			if pending := tracker.stats.pendingTxs.Load(); pending != int64(3-tt.included) {
+				t.Fatalf("expected %d pending transactions, got %d", 3-tt.included, pending)
+			}
+			if includedTxs := tracker.stats.includedTxs.Load(); includedTxs != int64(tt.included) {
+				t.Fatalf("expected %d included transactions in the stats, got %d", tt.included, includedTxs)
+			}
+		})
+	}
+}
+
+func TestTxTrackerConfirm(t *testing.T) {
+	tests := []struct {
+		name      string
+		number    uint64
+		confirmed int
+	}{
+		{name: "below every block", number: 4, confirmed: 0},
+		{name: "at the first block", number: 5, confirmed: 1},
+		{name: "between the blocks", number: 6, confirmed: 1},
+		{name: "at the last block", number: 7, confirmed: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTestTracker(3,
+				&client.Block{Number: 5, Transactions: []common.Hash{trackerTxID(0)}},
+				&client.Block{Number: 7, Transactions: []common.Hash{trackerTxID(1), trackerTxID(2)}},
+			)
+			confirmed := tracker.confirm(tt.number)
+			if len(confirmed) != tt.confirmed {
+				t.Fatalf("expected %d confirmed transactions, got %d", tt.confirmed, len(confirmed))
+			}
+			if again := tracker.confirm(tt.number); len(again) != 0 {
+				t.Fatalf("expected the transactions to be confirmed once, got %d again", len(again))
+			}
+			if included := tracker.stats.includedTxs.Load(); included != int64(3-tt.confirmed) {
+				t.Fatalf("expected %d transactions waiting for confirmation, got %d", 3-tt.confirmed, included)
+			}
+		})
+	}
+}
+
+func TestTxTrackerOrphan(t *testing.T) {
+	tests := []struct {
+		name     string
+		fork     uint64
+		orphaned int
+	}{
+		{name: "fork at the last block", fork: 7, orphaned: 0},
+		{name: "fork between the blocks", fork: 6, orphaned: 2},
+		{name: "fork below every block", fork: 4, orphaned: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newTxTracker(&chainStats{}, true)
+			for i := 0; i < 3; i++ {
+				tracker.add(trackerTxID(i), time.Now(), i, 1000)
+			}
+			tracker.include(&client.Block{Number: 5, Transactions: []common.Hash{trackerTxID(0)}})
+			tracker.include(&client.Block{Number: 7, Transactions: []common.Hash{trackerTxID(1), trackerTxID(2)}})
+
+			tracker.orphan(tt.fork)
+			if pending := tracker.stats.pendingTxs.Load(); pending != int64(tt.orphaned) {
+				t.Fatalf("expected %d pending transactions, got %d", tt.orphaned, pending)
+			}
+			if included := tracker.stats.includedTxs.Load(); included != int64(3-tt.orphaned) {
+				t.Fatalf("expected %d included transactions, got %d", 3-tt.orphaned, included)
+			}
+			if finalizing := len(tracker.finalizing); finalizing != 3-tt.orphaned {
+				t.Fatalf("expected %d transactions waiting for finality, got %d", 3-tt.orphaned, finalizing)
+			}
+
+			// the new chain includes the orphaned transactions again
+			included, _ := tracker.include(&client.Block{
+				Number:       8,
+				Transactions: []common.Hash{trackerTxID(0), trackerTxID(1), trackerTxID(2)},
+			})
+			if len(included) != tt.orphaned {
+				t.Fatalf("expected %d transactions included again, got %d", tt.orphaned, len(included))
+			}
+			for _, tx := range included {
+				if !tx.orphaned {
+					t.Fatal("expected the transactions included again to be marked as orphaned")
+				}
+			}
+		})
+	}
+}
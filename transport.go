@@ -0,0 +1,92 @@
+package xk6_vechain
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+)
+
+// instrumentedTransport reports the duration and response status of every request made to the node
+// at the URL. With a request log, every request is sent with a unique X-Request-Id header and recorded,
+// and the failed ones are returned as a requestError carrying the ID.
+type instrumentedTransport struct {
+	base     http.RoundTripper
+	url      string
+	report   func(url, call string, t time.Duration, status int)
+	requests *requestLog
+}
+
+func newInstrumentedTransport(url string) *instrumentedTransport {
+	return &instrumentedTransport{base: nodeTransport, url: url}
+}
+
+// RoundTrip implements http.RoundTripper. Failed requests are reported with a status of 0.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var id string
+	if t.requests != nil {
+		id = newRequestID()
+		req = req.Clone(req.Context())
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	started := time.Now()
+	res, err := t.base.RoundTrip(req)
+
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	call := req.Method + " " + route(req.URL.Path)
+	if t.report != nil {
+		t.report(t.url, call, time.Since(started), status)
+	}
+	if t.requests != nil {
+		t.requests.recordRequest(id, call, started, status)
+		if err != nil {
+			return nil, &requestError{id: id, err: err}
+		}
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			return nil, &requestError{id: id, err: responseError(res)}
+		}
+	}
+
+	return res, err
+}
+
+// responseError reads the failed response into the error the SDK returns for it.
+func responseError(res *http.Response) error {
+	defer res.Body.Close()
+	message := res.Status
+	if body, err := io.ReadAll(res.Body); err == nil {
+		message = string(body)
+	}
+	return &client.HttpError{Code: res.StatusCode, Status: res.Status, Message: message}
+}
+
+// route normalizes a request path so that metrics are not tagged with unbounded values,
+// e.g. /transactions/0xabc.../receipt becomes /transactions/{id}/receipt.
+func route(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "0x") || isRevisionKeyword(segment) {
+			segments[i] = "{id}"
+			continue
+		}
+		if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isRevisionKeyword(segment string) bool {
+	switch segment {
+	case "best", "finalized", "justified":
+		return true
+	}
+	return false
+}
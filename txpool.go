@@ -0,0 +1,224 @@
+package xk6_vechain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
+	"github.com/darrenvechain/xk6-vechain/toolchain"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// defaultPoolExpiration is the expiration of pre-signed transactions unless expiration is set, about two hours.
+const defaultPoolExpiration = 720
+
+// txPoolOptions configures newTxPool.
+type txPoolOptions struct {
+	// PerAccount is the number of transactions signed by every account.
+	PerAccount int `json:"perAccount"`
+	// Contract is the toolchain contract the transactions call, unless clauses are given.
+	Contract string `json:"contract,omitempty"`
+	// Clauses are the clauses of every transaction, in the same shape as sendDelegated.
+	Clauses []map[string]interface{} `json:"clauses,omitempty"`
+	// Span is the number of blocks the block references of the transactions are spread over, so that
+	// the transactions signed last only become valid later in the test.
+	Span uint32 `json:"span,omitempty"`
+	txParams
+}
+
+// pooledTx is a pre-signed transaction along with its validity window.
+type pooledTx struct {
+	raw        string
+	signer     int
+	blockRef   uint64
+	expiration uint64
+}
+
+// txPool is a pool of transactions signed ahead of the test, shared by every VU.
+type txPool struct {
+	txs     []pooledTx
+	next    atomic.Int64
+	expired atomic.Uint64
+}
+
+// TxPool hands out transactions signed ahead of the test, so that signing does not limit the request
+// rate of the iterations.
+type TxPool struct {
+	client *Client
+	pool   *txPool
+}
+
+// poolKey identifies a pool of a node.
+type poolKey struct {
+	url  string
+	name string
+}
+
+// txPools holds the pools of every node by name.
+var txPools sync.Map // poolKey -> *txPool
+
+// NewTxPool signs perAccount transactions with every account, calling the toolchain contract or made of
+// the clauses, and stores them in a pool shared by every VU under the name, to be fetched with txPool.
+// It is intended to be called from setup. The block references are spread over the next span blocks
+// and the pool hands the transactions out in that order, alternating between accounts, so that the
+// transactions signed last are still valid late in the test.
+func (c *Client) NewTxPool(name string, options map[string]interface{}) (*TxPool, error) {
+	var opts txPoolOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.PerAccount <= 0 {
+		return nil, errors.New("perAccount must be greater than 0")
+	}
+	if opts.BlockRef != "" {
+		return nil, errors.New("blockRef cannot be set, the pool spreads the block references over span")
+	}
+	if opts.Expiration == 0 {
+		opts.Expiration = defaultPoolExpiration
+	}
+
+	var (
+		clauses []*transaction.Clause
+		err     error
+	)
+	switch {
+	case opts.Contract != "" && len(opts.Clauses) > 0:
+		return nil, errors.New("either contract or clauses must be set, not both")
+	case opts.Contract != "":
+		var address common.Address
+		if address, err = parseAddress(opts.Contract); err != nil {
+			return nil, err
+		}
+		clauses, err = toolchain.Clauses(c.thor, address)
+	case len(opts.Clauses) > 0:
+		clauses, err = parseClauses(opts.Clauses)
+	default:
+		return nil, errors.New("either contract or clauses is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Gas == 0 {
+		simulation, err := c.thor.Transactor(clauses, c.managers[0].Address()).Simulate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate the gas of the pool: %w", err)
+		}
+		if !simulation.IsSuccess() {
+			return nil, fmt.Errorf("transactions of the pool would fail: %s", simulation.VMError())
+		}
+		opts.Gas = simulation.TotalGas()
+	}
+
+	best, err := c.thor.Blocks.Best()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := len(c.managers)
+	pool := &txPool{txs: make([]pooledTx, opts.PerAccount*accounts)}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for signer := 0; signer < accounts; signer++ {
+		wg.Add(1)
+		go func(signer int) {
+			defer wg.Done()
+			for i := 0; i < opts.PerAccount; i++ {
+				params := opts.txParams
+				blockRef := best.Number + uint64(i)*uint64(opts.Span)/uint64(opts.PerAccount)
+				params.BlockRef = strconv.FormatUint(blockRef, 10)
+
+				raw, err := c.signClauses(clauses, signer, params)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to sign for account %d: %w", signer, err)
+					}
+					mu.Unlock()
+					return
+				}
+				pool.txs[i*accounts+signer] = pooledTx{
+					raw:        raw,
+					signer:     signer,
+					blockRef:   blockRef,
+					expiration: uint64(opts.Expiration),
+				}
+			}
+		}(signer)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	txPools.Store(poolKey{url: c.opts.URL, name: name}, pool)
+	return &TxPool{client: c, pool: pool}, nil
+}
+
+// TxPool returns the pool stored under the name by newTxPool, in any VU.
+func (c *Client) TxPool(name string) (*TxPool, error) {
+	pool, ok := txPools.Load(poolKey{url: c.opts.URL, name: name})
+	if !ok {
+		return nil, fmt.Errorf("transaction pool %s does not exist", name)
+	}
+	return &TxPool{client: c, pool: pool.(*txPool)}, nil
+}
+
+// Next returns the next pre-signed transaction, hex encoded, skipping the ones that expired according
+// to the latest block seen by the block monitor.
+func (p *TxPool) Next() (string, error) {
+	tx, err := p.take()
+	if err != nil {
+		return "", err
+	}
+	return tx.raw, nil
+}
+
+// Send submits the next pre-signed transaction and returns its ID.
+func (p *TxPool) Send() (string, error) {
+	tx, err := p.take()
+	if err != nil {
+		return "", err
+	}
+
+	c := p.client
+	id, err := c.sendInFlight(tx.raw, "pre-signed transaction from account "+strconv.Itoa(tx.signer), tx.signer)
+	if err != nil {
+		return "", err
+	}
+	return id.Hex(), nil
+}
+
+// Remaining returns the number of transactions not handed out yet.
+func (p *TxPool) Remaining() int {
+	return max(len(p.pool.txs)-int(p.pool.next.Load()), 0)
+}
+
+// Expired returns the number of transactions skipped because they expired.
+func (p *TxPool) Expired() uint64 {
+	return p.pool.expired.Load()
+}
+
+// take hands out the next transaction that has not expired.
+func (p *TxPool) take() (pooledTx, error) {
+	best := statsFor(p.client.opts.URL).lastBlock.Load()
+	for {
+		i := p.pool.next.Add(1) - 1
+		if i >= int64(len(p.pool.txs)) {
+			return pooledTx{}, errors.New("transaction pool exhausted")
+		}
+		tx := p.pool.txs[i]
+		if best > tx.blockRef+tx.expiration {
+			p.pool.expired.Add(1)
+			continue
+		}
+		return tx, nil
+	}
+}
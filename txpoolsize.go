@@ -0,0 +1,49 @@
+package xk6_vechain
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// txPoolSampleInterval is how often the transaction pool of the node is sampled.
+const txPoolSampleInterval = 2 * time.Second
+
+// txPoolSlots holds, for each node URL, the index of the last sampled interval, see sampleEvery.
+var txPoolSlots sync.Map // url -> *atomic.Int64
+
+// txPoolStatus is the response of /node/txpool/status.
+type txPoolStatus struct {
+	Amount int `json:"amount"`
+}
+
+// sampleTxPoolSize periodically emits the number of transactions in the pool of the node in
+// vechain_txpool_size, so that backpressure shows before blocks fill. The pool endpoints of thor are
+// disabled unless the node runs with --api-enable-txpool, failures count in vechain_monitor_errors.
+// Each interval is sampled by a single client.
+func (c *Client) sampleTxPoolSize() {
+	slot, _ := txPoolSlots.LoadOrStore(c.opts.URL, new(atomic.Int64))
+	c.sampleEvery(txPoolSampleInterval, slot.(*atomic.Int64), func() {
+		size, err := c.txPoolSize()
+		if err != nil {
+			c.pushSample(c.metrics.MonitorErrors, 1, map[string]string{"monitor": "txpool"})
+			return
+		}
+		c.pushSample(c.metrics.TxPoolSize, float64(size), nil)
+	})
+}
+
+// txPoolSize returns the number of transactions in the pool of the node, from its status, or by listing
+// the pool on nodes that do not serve the status.
+func (c *Client) txPoolSize() (int, error) {
+	var status txPoolStatus
+	if err := c.getJSON("/node/txpool/status", &status); err == nil {
+		return status.Amount, nil
+	}
+
+	var ids []string
+	if err := c.getJSON("/node/txpool", &ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
@@ -1,32 +1,61 @@
 package xk6_vechain
 
 import (
-	"errors"
-	"math/big"
-	"strconv"
-	"sync"
+	"context"
+	"crypto/ecdsa"
+	"net/http"
+	"sync/atomic"
 	"time"
 
-	"github.com/darrenvechain/thor-go-sdk/builtins"
 	"github.com/darrenvechain/thor-go-sdk/crypto/hdwallet"
-	"github.com/darrenvechain/thor-go-sdk/crypto/transaction"
 	"github.com/darrenvechain/thor-go-sdk/thorgo"
 	"github.com/darrenvechain/thor-go-sdk/txmanager"
 	"github.com/darrenvechain/xk6-vechain/toolchain"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
 	"go.k6.io/k6/js/modules"
 	"go.k6.io/k6/metrics"
 )
 
 type Client struct {
-	wallet   *hdwallet.Wallet
-	thor     *thorgo.Thor
-	chainTag byte
-	vu       modules.VU
-	metrics  vechainMetrics
-	opts     *options
-	accounts int
-	managers []*txmanager.PKManager
+	ctx       context.Context // cancelled by close and at the end of the test, it stops the background goroutines
+	cancel    context.CancelFunc
+	wallet    *hdwallet.Wallet // nil when the accounts come from privateKeys or accountsKeystore
+	thor      *thorgo.Thor
+	http      *http.Client
+	chainTag  byte
+	vu        modules.VU
+	logger    logrus.FieldLogger // logger of the VU when the client was constructed
+	metrics   vechainMetrics
+	registry  *metrics.Registry
+	opts      *options
+	accounts  int
+	managers  []*txmanager.PKManager
+	keys      []*ecdsa.PrivateKey    // private key of each manager, for signing other than transactions
+	managed   map[common.Address]int // account index of each manager address
+	nodes     []submissionNode       // nodes transactions are routed to, when urls is set
+	tracker   *txTracker
+	signers   *atomic.Uint64
+	templates templates
+	gaps      subscriptionGaps
+	requests  *requestLog // requests made to the node, with requestIds
+	// partitioned is the slice of the accounts of the VU, once known, with the "partition" signerScope
+	partitioned atomic.Pointer[accountPartition]
+}
+
+// Close stops the block monitor and the other background goroutines of the client. They also stop when
+// the test ends. Calling it more than once is harmless.
+func (c *Client) Close() {
+	c.cancel()
+}
+
+// log returns the logger of the VU while it runs, and the one the client was constructed with otherwise,
+// e.g. in the init context.
+func (c *Client) log() logrus.FieldLogger {
+	if state := c.vu.State(); state != nil {
+		return state.Logger
+	}
+	return c.logger
 }
 
 func (c *Client) Accounts() []string {
@@ -37,168 +66,83 @@ func (c *Client) Accounts() []string {
 	return addresses
 }
 
+// DeployToolchain deploys the amount of toolchain contracts and returns their addresses. With the
+// deterministicDeployers option, each contract is deployed by a dedicated account derived from the
+// mnemonic, so that the addresses are the same on every run against a fresh node.
 func (c *Client) DeployToolchain(amount int) ([]string, error) {
-	contracts, err := toolchain.Deploy(c.thor, c.managers, amount)
+	started := time.Now()
+	var (
+		deployments []*toolchain.Deployment
+		err         error
+	)
+	if c.opts.DeterministicDeployers {
+		deployments, err = c.deployToolchainDeterministic(amount)
+	} else {
+		deployments, err = toolchain.Deploy(c.thor, c.managers, amount)
+	}
 	if err != nil {
 		return nil, err
 	}
-	addresses := make([]string, 0)
-	for _, contract := range contracts {
-		addresses = append(addresses, contract.Address.String())
-	}
-	return addresses, nil
-}
-
-func (c *Client) NewToolchainTransaction(address string) (string, error) {
-	addr := common.HexToAddress(address)
-	return toolchain.NewTransaction(c.thor, c.managers, addr)
-}
-
-// Fund sends VET and VTHO to the accounts after the index, funded by the accounts before the index.
-// The amount is the amount of VET & VTHO to send, represented as hex.
-// Example: thor solo only funds the first 10 accounts [0-9], so specify 10 as the start index.
-func (c *Client) Fund(start int, amount string) error {
-	if start > len(c.managers) {
-		return errors.New("start index is greater than the number of accounts")
-	}
-
-	// funder index -> clauses to send
-	clauses := make(map[int][]*transaction.Clause)
-	vtho := builtins.VTHO.Load(c.thor)
-
-	for i := start; i < len(c.managers); i++ {
-		fundee := c.managers[i].Address()
-		funderIndex := i % start
-
-		value := new(big.Int)
-		value.SetString(amount, 16)
-
-		vetClause := transaction.NewClause(&fundee).WithValue(value)
-		vthoClause, err := vtho.AsClause("transfer", fundee, value)
-		if err != nil {
-			return err
-		}
-
-		funderClauses := clauses[funderIndex]
-		if funderClauses == nil {
-			funderClauses = make([]*transaction.Clause, 0)
-		}
 
-		clauses[funderIndex] = append(funderClauses, vetClause, vthoClause)
+	var gasUsed uint64
+	addresses := make([]string, 0)
+	for _, deployment := range deployments {
+		addresses = append(addresses, deployment.Contract.Address.String())
+		gasUsed += deployment.GasUsed
+		c.record(deployment.TxID, "deploy toolchain")
+		abisFor(c.opts.URL).register(deployment.Contract.Address, deployment.Contract.ABI)
+
+		c.pushSample(c.metrics.DeployDuration, metrics.D(deployment.Duration), map[string]string{
+			"contract": "toolchain",
+			"phase":    "contract",
+		})
+		c.pushSample(c.metrics.DeployGas, float64(deployment.GasUsed), map[string]string{
+			"contract": "toolchain",
+			"phase":    "contract",
+		})
 	}
 
-	var (
-		wg        sync.WaitGroup
-		clauseErr error
-	)
-
-	for i, clauses := range clauses {
-		wg.Add(1)
-		manager := c.managers[i]
-		go func(i *txmanager.PKManager, clauses []*transaction.Clause) {
-			defer wg.Done()
-			for i := 0; i < len(clauses); i += 100 {
-				end := i + 100
-				if end > len(clauses) {
-					end = len(clauses)
-				}
-
-				tx, err := c.thor.Transactor(clauses[i:end], manager.Address()).Send(manager)
-				if err != nil {
-					clauseErr = err
-					return
-				}
+	c.pushSample(c.metrics.DeployDuration, metrics.D(time.Since(started)), map[string]string{
+		"contract": "toolchain",
+		"phase":    "total",
+	})
+	c.pushSample(c.metrics.DeployGas, float64(gasUsed), map[string]string{
+		"contract": "toolchain",
+		"phase":    "total",
+	})
 
-				_, err = tx.Wait()
-				if err != nil {
-					clauseErr = err
-					return
-				}
-			}
-		}(manager, clauses)
-	}
-
-	wg.Wait()
+	return addresses, nil
+}
 
-	if clauseErr != nil {
-		return clauseErr
+// NewToolchainTransaction builds a toolchain transaction signed by a random account and returns it hex
+// encoded. The options override the gas, gasPriceCoef, expiration, blockRef and dependsOn of the transaction.
+func (c *Client) NewToolchainTransaction(address string, options map[string]interface{}) (string, error) {
+	signer, err := c.randomSigner()
+	if err != nil {
+		return "", err
 	}
-
-	return nil
+	return c.NewToolchainTransactionFrom(address, signer, options)
 }
 
-var blocks sync.Map
-
-func (c *Client) pollForBlocks() {
-	prev, err := c.thor.Blocks.Best()
+// NewToolchainTransactionFrom builds a toolchain transaction signed by the account at the signer index.
+func (c *Client) NewToolchainTransactionFrom(address string, signer int, options map[string]interface{}) (string, error) {
+	params, err := parseTxParams(options)
 	if err != nil {
-		return
+		return "", err
 	}
+	clauses, err := toolchain.Clauses(c.thor, common.HexToAddress(address))
+	if err != nil {
+		return "", err
+	}
+	return c.signClauses(clauses, signer, params)
+}
 
-	for range time.Tick(500 * time.Millisecond) {
-		block, err := c.thor.Blocks.Best()
-		if err != nil {
-			continue
-		}
-
-		if block.Number > prev.Number {
-			blockTimestampDiff := time.Unix(int64(block.Timestamp), 0).Sub(time.Unix(int64(prev.Timestamp), 0))
-			tps := float64(len(block.Transactions)) / float64(blockTimestampDiff.Seconds())
-
-			prev = block
-
-			rootTS := metrics.NewRegistry().RootTagSet()
-			if c.vu != nil && c.vu.State() != nil && rootTS != nil {
-				if _, loaded := blocks.LoadOrStore(c.opts.URL+strconv.FormatUint(block.Number, 10), true); loaded {
-					// We already have a block number for this client, so we can skip this
-					continue
-				}
+// CurrentTps returns the transactions per second of the latest block seen by the block monitor.
+func (c *Client) CurrentTps() float64 {
+	return statsFor(c.opts.URL).currentTPS()
+}
 
-				metrics.PushIfNotDone(c.vu.Context(), c.vu.State().Samples, metrics.ConnectedSamples{
-					Samples: []metrics.Sample{
-						{
-							TimeSeries: metrics.TimeSeries{
-								Metric: c.metrics.Block,
-								Tags: rootTS.WithTagsFromMap(map[string]string{
-									"transactions": strconv.Itoa(len(block.Transactions)),
-									"gas_used":     strconv.Itoa(int(block.GasUsed)),
-									"gas_limit":    strconv.Itoa(int(block.GasLimit)),
-								}),
-							},
-							Value: float64(block.Number),
-							Time:  time.Now(),
-						},
-						{
-							TimeSeries: metrics.TimeSeries{
-								Metric: c.metrics.GasUsed,
-								Tags: rootTS.WithTagsFromMap(map[string]string{
-									"block": strconv.Itoa(int(block.Number)),
-								}),
-							},
-							Value: float64(block.GasUsed),
-							Time:  time.Now(),
-						},
-						{
-							TimeSeries: metrics.TimeSeries{
-								Metric: c.metrics.TPS,
-								Tags:   rootTS,
-							},
-							Value: tps,
-							Time:  time.Now(),
-						},
-						{
-							TimeSeries: metrics.TimeSeries{
-								Metric: c.metrics.BlockTime,
-								Tags: rootTS.WithTagsFromMap(map[string]string{
-									"block_timestamp_diff": blockTimestampDiff.String(),
-								}),
-							},
-							Value: float64(blockTimestampDiff.Milliseconds()),
-							Time:  time.Now(),
-						},
-					},
-				})
-			}
-		}
-	}
+// CurrentMempool returns the number of transactions sent to the node that are not yet included in a block.
+func (c *Client) CurrentMempool() int64 {
+	return statsFor(c.opts.URL).pendingTxs.Load()
 }
@@ -0,0 +1,184 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/darrenvechain/thor-go-sdk/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// expectedTransfer is a VET transfer the test believes it made.
+type expectedTransfer struct {
+	// TxID optionally pins the transfer to a transaction.
+	TxID      string `json:"txID,omitempty" js:"txID"`
+	Sender    string `json:"sender" js:"sender"`
+	Recipient string `json:"recipient" js:"recipient"`
+	// Amount is the amount of VET transferred, represented as hex.
+	Amount string `json:"amount" js:"amount"`
+}
+
+// key identifies the transfer, with or without its transaction ID.
+func (t expectedTransfer) key(withTx bool) string {
+	tx := ""
+	if withTx {
+		tx = strings.ToLower(t.TxID)
+	}
+	return strings.Join([]string{
+		tx,
+		strings.ToLower(t.Sender),
+		strings.ToLower(t.Recipient),
+		strings.ToLower(t.Amount),
+	}, "|")
+}
+
+// verifyOptions restricts the block range of the transfer logs that are verified.
+type verifyOptions struct {
+	From uint64 `json:"from,omitempty"`
+	To   uint64 `json:"to,omitempty"`
+}
+
+// TransferVerification is the result of reconciling expected transfers against the transfer logs.
+// Missing lists the expected transfers not found on chain, Unexpected lists the transfers between
+// managed accounts that were not expected.
+type TransferVerification struct {
+	OK         bool               `js:"ok"`
+	Expected   int                `js:"expected"`
+	Matched    int                `js:"matched"`
+	Missing    []expectedTransfer `js:"missing"`
+	Unexpected []expectedTransfer `js:"unexpected"`
+	Summary    string             `js:"summary"`
+}
+
+// VerifyTransfers reconciles the expected transfers against the VET transfer logs sent by the managed
+// accounts, counting the outcome in vechain_transfers_verified, vechain_transfers_missing and
+// vechain_transfers_unexpected. The returned summary can be appended to the output of handleSummary.
+func (c *Client) VerifyTransfers(expected []map[string]interface{}, options map[string]interface{}) (*TransferVerification, error) {
+	var opts verifyOptions
+	if err := decodeOptions(options, &opts); err != nil {
+		return nil, err
+	}
+
+	transfers := make([]expectedTransfer, len(expected))
+	for i, argument := range expected {
+		if err := decodeOptions(argument, &transfers[i]); err != nil {
+			return nil, fmt.Errorf("invalid transfer at index %d: %w", i, err)
+		}
+		amount, ok := new(big.Int).SetString(strings.TrimPrefix(transfers[i].Amount, "0x"), 16)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q of transfer at index %d, expected a hex value", transfers[i].Amount, i)
+		}
+		transfers[i].Amount = hexutil.EncodeBig(amount)
+	}
+
+	logs, err := filterTransfersAll(c.thor.Client, c.managedTransfersFilter(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make(map[common.Address]bool, len(c.managers))
+	for _, manager := range c.managers {
+		managed[manager.Address()] = true
+	}
+
+	// index the expected transfers, pinned ones by tx ID and the rest by sender, recipient and amount
+	outstanding := make(map[string]int)
+	for _, transfer := range transfers {
+		outstanding[transfer.key(transfer.TxID != "")]++
+	}
+
+	result := &TransferVerification{Expected: len(transfers)}
+	for _, log := range logs {
+		actual := expectedTransfer{
+			TxID:      log.Meta.TxID.Hex(),
+			Sender:    log.Sender.Hex(),
+			Recipient: log.Recipient.Hex(),
+			Amount:    hexutil.EncodeBig(log.Amount.ToInt()),
+		}
+
+		matched := false
+		for _, withTx := range []bool{true, false} {
+			if outstanding[actual.key(withTx)] > 0 {
+				outstanding[actual.key(withTx)]--
+				matched = true
+				break
+			}
+		}
+
+		if matched {
+			result.Matched++
+		} else if managed[log.Recipient] {
+			result.Unexpected = append(result.Unexpected, actual)
+		}
+	}
+
+	for _, transfer := range transfers {
+		key := transfer.key(transfer.TxID != "")
+		if outstanding[key] > 0 {
+			outstanding[key]--
+			result.Missing = append(result.Missing, transfer)
+		}
+	}
+
+	result.OK = len(result.Missing) == 0 && len(result.Unexpected) == 0
+	result.Summary = result.summary()
+
+	c.pushSample(c.metrics.TransfersVerified, float64(result.Matched), nil)
+	c.pushSample(c.metrics.TransfersMissing, float64(len(result.Missing)), nil)
+	c.pushSample(c.metrics.TransfersUnexpected, float64(len(result.Unexpected)), nil)
+
+	return result, nil
+}
+
+// managedTransfersFilter returns a filter matching every transfer sent by a managed account.
+func (c *Client) managedTransfersFilter(opts verifyOptions) *client.TransferFilter {
+	criteria := make([]client.TransferCriteria, 0, len(c.managers))
+	for _, manager := range c.managers {
+		sender := manager.Address()
+		criteria = append(criteria, client.TransferCriteria{Sender: &sender})
+	}
+
+	filter := &client.TransferFilter{Criteria: &criteria}
+	if opts.From > 0 || opts.To > 0 {
+		unit := "block"
+		to := opts.To
+		if to == 0 {
+			to = uint64(1<<32 - 1)
+		}
+		filter.Range = &client.FilterRange{Unit: &unit, From: &opts.From, To: &to}
+	}
+	return filter
+}
+
+// summary renders the verification as a text section for the end of test summary.
+func (v *TransferVerification) summary() string {
+	var b strings.Builder
+	verdict := "PASS"
+	if !v.OK {
+		verdict = "FAIL"
+	}
+
+	fmt.Fprintf(&b, "transfer verification: %s\n", verdict)
+	fmt.Fprintf(&b, "  expected...: %d\n", v.Expected)
+	fmt.Fprintf(&b, "  matched....: %d\n", v.Matched)
+	fmt.Fprintf(&b, "  missing....: %d\n", len(v.Missing))
+	fmt.Fprintf(&b, "  unexpected.: %d\n", len(v.Unexpected))
+	for _, t := range v.Missing {
+		fmt.Fprintf(&b, "  - missing %s VET from %s to %s\n", amountString(t.Amount), t.Sender, t.Recipient)
+	}
+	for _, t := range v.Unexpected {
+		fmt.Fprintf(&b, "  - unexpected %s VET from %s to %s in %s\n", amountString(t.Amount), t.Sender, t.Recipient, t.TxID)
+	}
+	return b.String()
+}
+
+// amountString renders a hex encoded amount in decimal.
+func amountString(amount string) string {
+	value, err := hexutil.DecodeBig(amount)
+	if err != nil {
+		return amount
+	}
+	return value.String()
+}
@@ -0,0 +1,105 @@
+package xk6_vechain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.k6.io/k6/metrics"
+)
+
+// Workload is a load pattern implemented in Go and compiled into the binary, for workloads that are
+// too costly to drive from JS. Scripts get a handle with client.workload(name, params) and call its
+// setup, iterate and teardown. Every VU gets its own instance, so the instance used in setup is not
+// the one used in the iterations: state shared between them has to live outside of the instance.
+type Workload interface {
+	Setup(ctx *WorkloadContext) error
+	Iterate(ctx *WorkloadContext) error
+	Teardown(ctx *WorkloadContext) error
+}
+
+var (
+	workloadsMu sync.RWMutex
+	workloads   = make(map[string]func() Workload)
+)
+
+// RegisterWorkload makes the workload available to scripts under the name. It is meant to be called
+// from the init function of the package implementing the workload, and panics on a duplicate name.
+func RegisterWorkload(name string, factory func() Workload) {
+	workloadsMu.Lock()
+	defer workloadsMu.Unlock()
+	if _, exists := workloads[name]; exists {
+		panic(fmt.Sprintf("workload %s is already registered", name))
+	}
+	workloads[name] = factory
+}
+
+// WorkloadContext is what a workload gets access to: the client of the VU, with its accounts and its
+// connection to the node, and the params the script passed.
+type WorkloadContext struct {
+	Client *Client
+	Params map[string]interface{}
+}
+
+// Metric returns the custom metric with the name, registering it on first use. Metrics used in
+// thresholds must be registered during setup, which runs in the init phase of the VU.
+func (ctx *WorkloadContext) Metric(name string, metricType metrics.MetricType, valueType metrics.ValueType) (*metrics.Metric, error) {
+	return ctx.Client.registry.NewMetric(name, metricType, valueType)
+}
+
+// Push pushes a sample of the metric, tagged with the VU tags, the node, plus the given tags.
+func (ctx *WorkloadContext) Push(metric *metrics.Metric, value float64, tags map[string]string) {
+	ctx.Client.pushSample(metric, value, tags)
+}
+
+// WorkloadHandle runs a registered workload from JS.
+type WorkloadHandle struct {
+	workload Workload
+	ctx      *WorkloadContext
+}
+
+// Workload returns a handle to a new instance of the registered workload, configured with the params.
+func (c *Client) Workload(name string, params map[string]interface{}) (*WorkloadHandle, error) {
+	workloadsMu.RLock()
+	factory, ok := workloads[name]
+	workloadsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("workload %s is not registered, registered workloads: %s", name, registeredWorkloads())
+	}
+
+	return &WorkloadHandle{
+		workload: factory(),
+		ctx:      &WorkloadContext{Client: c, Params: params},
+	}, nil
+}
+
+// Setup runs the setup of the workload.
+func (h *WorkloadHandle) Setup() error {
+	return h.workload.Setup(h.ctx)
+}
+
+// Iterate runs an iteration of the workload.
+func (h *WorkloadHandle) Iterate() error {
+	return h.workload.Iterate(h.ctx)
+}
+
+// Teardown runs the teardown of the workload.
+func (h *WorkloadHandle) Teardown() error {
+	return h.workload.Teardown(h.ctx)
+}
+
+// registeredWorkloads returns the names of the registered workloads.
+func registeredWorkloads() string {
+	workloadsMu.RLock()
+	defer workloadsMu.RUnlock()
+	names := make([]string, 0, len(workloads))
+	for name := range workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}